@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -16,15 +17,21 @@ func main() {
 	lexer, err := NewLexer(os.Args[1])
 	if err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 	defer lexer.Close()
 
 	parser, err := NewParser(os.Args[1])
 	if err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 
-	slicetokens := []Token{}
+	// Tokens of every channel are kept, the parser only consumes the default
+	// one and attaches the rest (white spaces, comments) to the parse tree.
+	// Input is parsed line by line, a line that can't be parsed on its own is
+	// retried along with the next ones.
+	pending := []Token{}
 	for {
 		token, err := lexer.GetNextToken()
 		if err != nil {
@@ -34,34 +41,32 @@ func main() {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
+		pending = append(pending, token)
 
-		_, ok := parser.parsedefinition.IgnoredSymbols[token.TokenID]
-
-		if !ok {
-			slicetokens = append(slicetokens, token)
-		}
-
-		if token.Value == "\n" {
-			result := parser.ParseInput(slicetokens, parser.parsedefinition.Terminals, *parser.parsedefinition)
-
-			if len(*result) == 0 {
-				slicetokens = []Token{}
-			} else {
-				slicetokens = *result // retry only unparsed
+		if parser.Channel(token) != DEFAULT_CHANNEL && strings.Contains(token.Value, "\n") {
+			if tree, err := parser.Parse(pending); err == nil {
+				printAccepted(tree)
+				pending = []Token{}
 			}
 		}
-
 	}
 
-	if len(slicetokens) >= 0 {
-		slicetokens = *parser.ParseInput(slicetokens, parser.parsedefinition.Terminals, *parser.parsedefinition)
+	if len(parser.SplitChannels(pending)[DEFAULT_CHANNEL]) > 0 {
+		tree, err := parser.Parse(pending)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		printAccepted(tree)
 	}
 
-	if len(slicetokens) == 0 {
-		fmt.Println("ALL LINES ARE ACCEPTED")
-	} else {
-		fmt.Printf("\nERROR PARSING FROM %d, to %d", slicetokens[0].Offset, slicetokens[len(slicetokens)-1].Offset)
-		fmt.Println(slicetokens)
-	}
+	fmt.Println("ALL LINES ARE ACCEPTED")
+}
 
+func printAccepted(tree *ParseNode) {
+	values := []string{}
+	for _, leaf := range tree.Leaves() {
+		values = append(values, leaf.Token.Value)
+	}
+	fmt.Printf("INPUT ACCEPTED as %s: %s\n", tree.Symbol.Value, strings.Join(values, " "))
 }
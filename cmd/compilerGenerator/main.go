@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	lex "github.com/DanielRasho/Parser/internal/Lexer/Generator"
+	Lex_writer "github.com/DanielRasho/Parser/internal/Lexer/Generator/LexWriter"
 	parser "github.com/DanielRasho/Parser/internal/Parser/Generator"
 )
 
@@ -16,6 +17,8 @@ func main() {
 	yaparFile := flag.String("p", "", "Parser file path")
 	outputFlag := flag.String("d", "", "Output file path")
 	verbose := flag.Bool("verbose", true, "Render automata diagrams")
+	emitFlag := flag.String("emit", "table", "How the lexer DFA is written: table or direct")
+	modeFlag := flag.String("mode", "slr", "Kind of parser: slr, ll1 or glr")
 
 	// Parse the command line flags
 	flag.Parse()
@@ -26,6 +29,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	mode, err := Lex_writer.ParseEmitMode(*emitFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	parserMode, err := parser.ParseMode(*modeFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	parserTemplate := parserMode.Template()
+
 	// Print the values of the flags (just as an example)
 	fmt.Printf("Yalex file: %s\n", *yalexFile)
 	fmt.Printf("Yapar file: %s\n", *yaparFile)
@@ -36,14 +52,16 @@ func main() {
 	parserFile := filepath.Join(*outputFlag, "parser.go")
 
 	// CODE FOR GENERATING LEXER ...
-	err := lex.Compile(*yalexFile, lexerFile, *verbose, *verbose)
+	err = lex.Compile(*yalexFile, lexerFile, *verbose, *verbose, mode)
 	if err != nil {
 		fmt.Println(err)
+		os.Exit(1)
 	}
 
 	// CODE FOR GENERATING PARSER ...
-	err = parser.Compile(*yaparFile, "./template/ParserTemplate.go", parserFile, *verbose)
+	err = parser.Compile(*yaparFile, parserTemplate, parserFile, *verbose, parserMode)
 	if err != nil {
 		fmt.Println(err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Size every example is repeated up to, so the lexer setup doesn't dominate.
+const BENCH_INPUT_SIZE = 1 << 20
+
+// Benchmarks the generated lexer over every example code. Since the lexer must
+// be generated first, run it as:
+//
+//	task lex:bench
+//
+// which generates cmd/lexer/lexer.go from examples/hard.lex. Run it with
+// EMIT=direct to compare the direct coded lexer against the tables.
+//
+// Examples written for other languages are still lexed, in recovery mode.
+func BenchmarkLexer(b *testing.B) {
+	files, err := filepath.Glob("../../examples/*.code")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		input := bytes.Repeat(content, BENCH_INPUT_SIZE/len(content)+1)
+		path := filepath.Join(b.TempDir(), filepath.Base(file))
+		if err := os.WriteFile(path, input, 0o644); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(strings.TrimSuffix(filepath.Base(file), ".code"), func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lexer, err := NewLexer(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				lexer.EnableRecovery()
+				for {
+					_, err := lexer.GetNextToken()
+					if err == io.EOF {
+						break
+					} else if err != nil {
+						b.Fatal(err)
+					}
+				}
+				lexer.Close()
+			}
+		})
+	}
+}
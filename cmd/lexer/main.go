@@ -16,9 +16,13 @@ func main() {
 	lexer, err := NewLexer(os.Args[1])
 	if err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 	defer lexer.Close()
 
+	// Keep scanning after an error, so all of them are reported at once
+	lexer.EnableRecovery()
+
 	for {
 		token, err := lexer.GetNextToken()
 		if err != nil {
@@ -31,4 +35,12 @@ func main() {
 		fmt.Print(token.String() + "\n")
 	}
 
+	if errors := lexer.Errors(); len(errors) > 0 {
+		fmt.Printf("\nFound %d lexical errors:\n", len(errors))
+		for _, err := range errors {
+			fmt.Println(err.Error())
+		}
+		os.Exit(1)
+	}
+
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	generator "github.com/DanielRasho/Parser/internal/Lexer/Generator"
+	Lex_writer "github.com/DanielRasho/Parser/internal/Lexer/Generator/LexWriter"
 )
 
 func main() {
@@ -13,6 +14,7 @@ func main() {
 	fileFlag := flag.String("f", "", "Parser file path")
 	outputFlag := flag.String("o", "", "Output file path")
 	diagramFlag := flag.Bool("diagram", true, "Render automata diagrams")
+	emitFlag := flag.String("emit", "table", "How the DFA is written: table or direct")
 
 	// Parse the command line flags
 	flag.Parse()
@@ -23,13 +25,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	mode, err := Lex_writer.ParseEmitMode(*emitFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	// Print the values of the flags (just as an example)
 	fmt.Printf("Input file: %s\n", *fileFlag)
 	fmt.Printf("Output file: %s\n", *outputFlag)
 	fmt.Printf("Render diagramas: %t\n", *diagramFlag)
+	fmt.Printf("Emit mode: %s\n", *emitFlag)
 
 	// CODE FOR GENERATING LEXER ...
-	err := generator.Compile(*fileFlag, *outputFlag, true, *diagramFlag)
+	err = generator.Compile(*fileFlag, *outputFlag, true, *diagramFlag, mode)
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	generator "github.com/DanielRasho/Parser/internal/Lexer/Generator"
+	yalex_reader "github.com/DanielRasho/Parser/internal/Lexer/Generator/YALexReader"
+)
+
+const USAGE = `Usage: task lex:tools -- <command> [arguments]
+
+Commands:
+  equiv <a.lex> <b.lex>   Checks that both lexers tokenize every input the same way
+  sample [flags] <a.lex>  Shows lexemes that produce the token of each rule, -h for its flags`
+
+func main() {
+	flag.Usage = func() { fmt.Println(USAGE) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch flag.Arg(0) {
+	case "equiv":
+		equiv(flag.Args()[1:])
+	case "sample":
+		sample(flag.Args()[1:])
+	default:
+		fmt.Printf("Unknown command %s\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// A lexer definition alongside its DFA.
+type lexer struct {
+	path       string
+	definition *yalex_reader.YALexDefinition
+	automata   *dfa.DFA
+}
+
+func loadLexer(path string) lexer {
+	definition, err := yalex_reader.Parse(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	automata, err := generator.BuildDFA(definition)
+	if err != nil {
+		fmt.Printf("%s: %s\n", path, err)
+		os.Exit(2)
+	}
+	return lexer{path: path, definition: definition, automata: automata}
+}
+
+// Exits with 0 when both lexers are equivalent, 1 when they are not.
+func equiv(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: task lex:tools -- equiv <a.lex> <b.lex>")
+		os.Exit(2)
+	}
+	a, b := loadLexer(args[0]), loadLexer(args[1])
+
+	input, equivalent := dfa.Equivalent(a.automata, b.automata)
+	if equivalent {
+		fmt.Printf("%s and %s are equivalent\n", a.path, b.path)
+		return
+	}
+
+	fmt.Printf("%s and %s are not equivalent, the shortest input they tokenize differently is %q\n", a.path, b.path, input)
+	for _, l := range []lexer{a, b} {
+		fmt.Printf("\n%s:\n", l.path)
+		for _, lexeme := range l.automata.Tokenize(input) {
+			fmt.Printf("\t%s\n", l.describe(lexeme))
+		}
+	}
+	os.Exit(1)
+}
+
+// Ex: "while" matched by "while" at 12:1 { return WHILE }
+func (l lexer) describe(lexeme dfa.Lexeme) string {
+	if lexeme.Rule == -1 {
+		return fmt.Sprintf("%q matched by no rule", lexeme.Text)
+	}
+	rule := l.definition.Rules[lexeme.Rule]
+	return fmt.Sprintf("%q matched by %s at %d:%d %s", lexeme.Text, rule.Source, rule.Pos.Line, rule.Pos.Column, strings.TrimSpace(rule.Action))
+}
+
+// Prints samples of every rule, or only of the one chosen.
+func sample(args []string) {
+	flags := flag.NewFlagSet("sample", flag.ExitOnError)
+	count := flags.Int("n", 5, "Samples of each rule")
+	maxLength := flags.Int("max", 16, "Maximum runes of a sample")
+	random := flags.Bool("random", false, "Random samples instead of the shortest ones")
+	seed := flags.Int64("seed", 0, "Seed of the random samples, the current time when 0")
+	ruleFlag := flags.Int("rule", -1, "Only sample the rule with this priority (0 for the first rule)")
+	flags.Usage = func() {
+		fmt.Println("Usage: task lex:tools -- sample [flags] <a.lex>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	l := loadLexer(flags.Arg(0))
+	if *ruleFlag < -1 || *ruleFlag >= len(l.definition.Rules) {
+		fmt.Printf("%s has no rule %d, its rules go from 0 to %d\n", l.path, *ruleFlag, len(l.definition.Rules)-1)
+		os.Exit(2)
+	}
+
+	options := dfa.SampleOptions{Count: *count, MaxLength: *maxLength}
+	if *random {
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		options.Random = rand.New(rand.NewSource(*seed))
+	}
+
+	for priority, rule := range l.definition.Rules {
+		if *ruleFlag != -1 && priority != *ruleFlag {
+			continue
+		}
+		fmt.Printf("%s at %d:%d %s\n", rule.Source, rule.Pos.Line, rule.Pos.Column, strings.TrimSpace(rule.Action))
+		samples := l.automata.Samples(priority, options)
+		if len(samples) == 0 {
+			fmt.Printf("\tno lexeme of at most %d runes produces this token\n", *maxLength)
+		}
+		for _, sample := range samples {
+			fmt.Printf("\t%q\n", sample)
+		}
+	}
+}
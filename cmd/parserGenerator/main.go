@@ -1,37 +1,90 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 
+	analysis "github.com/DanielRasho/Parser/internal/Parser/Analysis"
 	parser "github.com/DanielRasho/Parser/internal/Parser/Generator"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
 )
 
 func main() {
 	// Define the flags
 	fileFlag := flag.String("f", "", "Yalex file path")
 	outputFlag := flag.String("o", "", "Output file path")
-	template := flag.String("t", "", "Template Parser")
+	template := flag.String("t", "", "Template Parser, by default the one of the mode")
 	diagramFlag := flag.Bool("diagram", true, "Render automata diagrams")
+	modeFlag := flag.String("mode", "slr", "Kind of parser: slr, ll1 or glr")
+	warningsFlag := flag.String("warnings", "text", "How to print the problems found on the grammar to stderr: text, json or off")
+	strictFlag := flag.Bool("strict", false, "Fails without generating the parser when the grammar has warnings")
 
 	// Parse the command line flags
 	flag.Parse()
 
 	// Check if both flags are provided, if not print usage
-	if *fileFlag == "" || *outputFlag == "" || *template == "" {
-		fmt.Println("Usage: task lex:generate -- -f <input-file> -o <output-file> -t <template-parser>")
+	if *fileFlag == "" || *outputFlag == "" {
+		fmt.Println("Usage: task parser:generate -- -f <input-file> -o <output-file> [-t <template-parser>] [-mode slr|ll1|glr] [-warnings text|json|off] [-strict]")
 		os.Exit(1)
 	}
 
+	mode, err := parser.ParseMode(*modeFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *template == "" {
+		*template = mode.Template()
+	} else if err := mode.CheckTemplate(*template); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *warningsFlag != "text" && *warningsFlag != "json" && *warningsFlag != "off" {
+		fmt.Printf("unknown warnings format %q, expected text, json or off\n", *warningsFlag)
+		os.Exit(1)
+	}
+	// A definition with errors is left to Compile, which reports them
+	if definition, err := reader.Parse(*fileFlag); err == nil {
+		warnings := analysis.Analyze(definition)
+		printWarnings(warnings, *warningsFlag)
+		if *strictFlag && len(warnings) > 0 {
+			fmt.Printf("%s has %d warnings, the parser is not generated with -strict\n", *fileFlag, len(warnings))
+			os.Exit(1)
+		}
+	}
+
 	// Print the values of the flags (just as an example)
 	fmt.Printf("Input file: %s\n", *fileFlag)
 	fmt.Printf("Output file: %s\n", *outputFlag)
 	fmt.Printf("Render diagramas: %t\n", *diagramFlag)
+	fmt.Printf("Mode: %s\n", mode)
+	fmt.Printf("Template: %s\n", *template)
 
 	// CODE FOR GENERATING LPARSER ...
-	err := parser.Compile(*fileFlag, *template, *outputFlag, true)
+	err = parser.Compile(*fileFlag, *template, *outputFlag, true, mode)
 	if err != nil {
 		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// Writes the warnings on stderr, one per line or as a JSON array to be read by
+// other tools. The array is written even when it is empty.
+func printWarnings(warnings []analysis.Warning, format string) {
+	switch format {
+	case "text":
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, w)
+		}
+	case "json":
+		encoder := json.NewEncoder(os.Stderr)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(warnings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
 	}
 }
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	lex "github.com/DanielRasho/Parser/internal/Lexer/Generator"
+	yalex_reader "github.com/DanielRasho/Parser/internal/Lexer/Generator/YALexReader"
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	earley "github.com/DanielRasho/Parser/internal/Parser/Earley"
+	fuzzer "github.com/DanielRasho/Parser/internal/Parser/Fuzzer"
+	glr "github.com/DanielRasho/Parser/internal/Parser/GLR"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	predictive "github.com/DanielRasho/Parser/internal/Parser/PredictiveTable"
+	transform "github.com/DanielRasho/Parser/internal/Parser/Transform"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+	"github.com/DanielRasho/Parser/internal/Parser/automata"
+)
+
+const USAGE = `Usage: task parser:tools -- <command> [arguments]
+
+Commands:
+  generate [flags]   Writes random sentences of a grammar, -h for its flags
+  cover [flags]      Writes inputs going through every cell of the parsing tables, -h for its flags
+  transform [flags]  Removes left recursion and left factors a grammar, -h for its flags
+  earley [flags]     Parses sentences with any grammar, checking the LR tables with -check, -h for its flags`
+
+func main() {
+	flag.Usage = func() { fmt.Println(USAGE) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch flag.Arg(0) {
+	case "generate":
+		generate(flag.Args()[1:])
+	case "cover":
+		cover(flag.Args()[1:])
+	case "transform":
+		rewrite(flag.Args()[1:])
+	case "earley":
+		prototype(flag.Args()[1:])
+	default:
+		fmt.Printf("Unknown command %s\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// Prints a sentence of the grammar on each line. With a lexer, terminals are
+// written as lexemes of the rules that return them, otherwise by their name.
+func generate(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	yaparFile := flags.String("p", "", "Yapar file")
+	yalexFile := flags.String("l", "", "Yalex file to write terminals as lexemes (optional)")
+	count := flags.Int("n", 10, "Number of sentences")
+	depth := flags.Int("depth", 10, "Maximum depth of a derivation")
+	seed := flags.Int64("seed", 0, "Random seed, the current time when 0")
+	separator := flags.String("sep", " ", "Written between lexemes")
+	flags.Usage = func() {
+		fmt.Println("Usage: task parser:tools -- generate -p <yapar-file> [-l <yalex-file>] [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if *yaparFile == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	options := fuzzer.Options{MaxDepth: *depth, Random: rand.New(rand.NewSource(*seed))}
+
+	definition, err := reader.Parse(*yaparFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	f, err := fuzzer.NewFuzzer(definition)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", *yaparFile, err)
+		os.Exit(2)
+	}
+
+	if *yalexFile != "" {
+		samples := dfa.SampleOptions{Count: 20, MaxLength: 12, Random: options.Random}
+		options.Lexemes = loadLexemes(*yalexFile, definition, samples)
+	}
+
+	for i := 0; i < *count; i++ {
+		fmt.Println(f.Generate(options).Text(*separator))
+	}
+}
+
+// Writes inputs that go through every cell of the parsing tables, to check
+// changes of the parser against. Rejected inputs come with the empty cell the
+// parser stops on.
+func cover(args []string) {
+	flags := flag.NewFlagSet("cover", flag.ExitOnError)
+	yaparFile := flags.String("p", "", "Yapar file")
+	yalexFile := flags.String("l", "", "Yalex file to write terminals as lexemes (optional)")
+	directory := flags.String("d", "", "Writes each input to a file on <dir>/valid and <dir>/invalid instead")
+	separator := flags.String("sep", " ", "Written between lexemes")
+	flags.Usage = func() {
+		fmt.Println("Usage: task parser:tools -- cover -p <yapar-file> [-l <yalex-file>] [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if *yaparFile == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	definition, err := reader.Parse(*yaparFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, production := range definition.Productions {
+		if production.IsEmpty() {
+			fmt.Fprintf(os.Stderr, "%s: cover needs the SLR tables, which don't support ε-productions like %s\n", *yaparFile, production.String())
+			os.Exit(2)
+		}
+	}
+	first := table.GetFirst(definition)
+	follow := table.GetFollow(definition, first)
+	transit, gotos, err := table.NewTable(automata.NewAutomata(definition, false), first, follow, *definition)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", *yaparFile, err)
+		os.Exit(2)
+	}
+	if conflicts := table.Conflicts(*transit); len(conflicts) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: cover needs SLR tables without conflicts, %d found:\n", *yaparFile, len(conflicts))
+		for _, c := range conflicts {
+			fmt.Fprintln(os.Stderr, c.Describe(definition))
+		}
+		os.Exit(2)
+	}
+	coverage := fuzzer.Cover(definition, transit, gotos)
+
+	lexemes := map[string][]string{}
+	if *yalexFile != "" {
+		lexemes = loadLexemes(*yalexFile, definition, dfa.SampleOptions{Count: 1, MaxLength: 12})
+	}
+
+	if *directory == "" {
+		fmt.Printf("Accepted (%d):\n", len(coverage.Valid))
+		for _, input := range coverage.Valid {
+			fmt.Printf("\t%s\n", input.Text(lexemes, *separator))
+		}
+		fmt.Printf("Rejected (%d):\n", len(coverage.Invalid))
+		for _, input := range coverage.Invalid {
+			fmt.Printf("\t%s\t(state %s on %s)\n", input.Text(lexemes, *separator), input.Error.State, input.Error.Symbol)
+		}
+	} else {
+		for _, corpus := range []struct {
+			name   string
+			inputs []fuzzer.Case
+		}{{"valid", coverage.Valid}, {"invalid", coverage.Invalid}} {
+			folder := filepath.Join(*directory, corpus.name)
+			if err := os.MkdirAll(folder, 0755); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			width := len(strconv.Itoa(len(corpus.inputs)))
+			for i, input := range corpus.inputs {
+				file := filepath.Join(folder, fmt.Sprintf("%0*d.txt", width, i+1))
+				if err := os.WriteFile(file, []byte(input.Text(lexemes, *separator)+"\n"), 0644); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(2)
+				}
+			}
+		}
+		fmt.Printf("Wrote %d accepted and %d rejected inputs to %s\n", len(coverage.Valid), len(coverage.Invalid), *directory)
+	}
+
+	// Usually reductions on a FOLLOW terminal that can't come after that state
+	for _, c := range coverage.Missing {
+		fmt.Fprintf(os.Stderr, "warning: no input goes through state %s on %s\n", c.State, c.Symbol)
+	}
+}
+
+// Prints a grammar without left recursion and left factored, in the yapar syntax.
+// Warns when the result is still not LL(1).
+func rewrite(args []string) {
+	flags := flag.NewFlagSet("transform", flag.ExitOnError)
+	yaparFile := flags.String("p", "", "Yapar file")
+	recursion := flags.Bool("recursion", true, "Removes left recursion")
+	factor := flags.Bool("factor", true, "Left factors the grammar")
+	flags.Usage = func() {
+		fmt.Println("Usage: task parser:tools -- transform -p <yapar-file> [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if *yaparFile == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	definition, err := reader.Parse(*yaparFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	result := &transform.Result{Definition: definition}
+	if *recursion {
+		result, err = transform.RemoveLeftRecursion(definition)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", *yaparFile, err)
+			os.Exit(2)
+		}
+	}
+	if *factor {
+		factored := transform.LeftFactor(result.Definition)
+		factored.Helpers = append(result.Helpers, factored.Helpers...)
+		result = factored
+	}
+	fmt.Print(result.Format())
+
+	first := table.GetFirst(result.Definition)
+	follow := table.GetFollow(result.Definition, first)
+	if _, conflicts := predictive.NewTable(result.Definition, first, follow); len(conflicts) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: the grammar is still not LL(1), %d conflicts:\n", len(conflicts))
+		for _, c := range conflicts {
+			fmt.Fprintln(os.Stderr, c.Describe(result.Definition))
+		}
+	}
+}
+
+// Parses each line of the input, terminal names separated by spaces, with an
+// Earley parser, which takes grammars with conflicts. With -check the LR tables
+// parse them too, reporting the lines where they don't agree.
+func prototype(args []string) {
+	flags := flag.NewFlagSet("earley", flag.ExitOnError)
+	yaparFile := flags.String("p", "", "Yapar file")
+	check := flags.Bool("check", false, "Fails when the LR tables accept or reject a line differently")
+	flags.Usage = func() {
+		fmt.Println("Usage: task parser:tools -- earley -p <yapar-file> [flags] [input-file]")
+		fmt.Println("Reads the standard input when no file is given, like the output of generate.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if *yaparFile == "" || flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	definition, err := reader.Parse(*yaparFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	p := earley.NewParser(definition)
+
+	var lr *glr.Parser
+	if *check {
+		for _, production := range definition.Productions {
+			if production.IsEmpty() {
+				fmt.Fprintf(os.Stderr, "%s: -check needs the SLR tables, which don't support ε-productions like %s\n", *yaparFile, production.String())
+				os.Exit(2)
+			}
+		}
+		first := table.GetFirst(definition)
+		follow := table.GetFollow(definition, first)
+		transit, gotos, err := table.NewTable(automata.NewAutomata(definition, false), first, follow, *definition)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", *yaparFile, err)
+			os.Exit(2)
+		}
+		// With conflicts every movement is tried, like the glr mode does
+		lr = glr.NewParser(definition, *transit, *gotos)
+	}
+
+	input := os.Stdin
+	if flags.NArg() == 1 {
+		input, err = os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		defer input.Close()
+	}
+
+	disagreements := 0
+	scanner := bufio.NewScanner(input)
+	for line := 1; scanner.Scan(); line++ {
+		tokens := strings.Fields(scanner.Text())
+		forest, err := p.Parse(tokens)
+		var ambiguity *glr.AmbiguityError
+		switch {
+		case err != nil:
+			fmt.Printf("%d: rejected, %s\n", line, err)
+		case forest.Count() > 1:
+			_, err := forest.Tree()
+			errors.As(err, &ambiguity)
+			fmt.Printf("%d: ambiguous, %d trees, %s\n", line, forest.Count(), ambiguity)
+		default:
+			tree, err := forest.Tree()
+			if err != nil {
+				fmt.Printf("%d: accepted, %s\n", line, err)
+			} else {
+				fmt.Printf("%d: accepted, %s\n", line, tree)
+			}
+		}
+
+		if lr != nil {
+			if _, lrErr := lr.Parse(tokens); (lrErr == nil) != (err == nil) {
+				disagreements++
+				fmt.Fprintf(os.Stderr, "line %d: the LR tables say %v, Earley says %v\n", line, verdict(lrErr), verdict(err))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if disagreements > 0 {
+		fmt.Fprintf(os.Stderr, "%d lines parsed differently\n", disagreements)
+		os.Exit(1)
+	}
+}
+
+func verdict(err error) string {
+	if err == nil {
+		return "accepted"
+	}
+	return "rejected (" + err.Error() + ")"
+}
+
+// Samples the rules of a yalex file, warning about terminals of the grammar no
+// rule returns.
+func loadLexemes(yalexFile string, definition *parser.ParserDefinition, options dfa.SampleOptions) map[string][]string {
+	yal, err := yalex_reader.Parse(yalexFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	automata, err := lex.BuildDFA(yal)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", yalexFile, err)
+		os.Exit(2)
+	}
+	lexemes := fuzzer.Lexemes(yal, automata, options)
+	for _, terminal := range definition.Terminals {
+		if _, ignored := definition.IgnoredSymbol[terminal.Id]; !ignored && len(lexemes[terminal.Value]) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: no rule of %s returns %s, it is written by its name\n", yalexFile, terminal.Value)
+		}
+	}
+	return lexemes
+}
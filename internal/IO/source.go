@@ -0,0 +1,164 @@
+package io
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position of a character within a source file. Lines and columns start at 1,
+// columns are counted in runes.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Source holds the whole content of a definition file (yalex, yapar) so
+// readers can move freely over it and report errors with its position.
+type Source struct {
+	Name    string
+	Content string
+	// Byte offset where each line starts
+	lineStarts []int
+}
+
+// ReadSource loads a file in memory. Windows line endings are normalized to "\n".
+func ReadSource(path string) (*Source, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSource(path, string(content)), nil
+}
+
+// NewSource creates a Source from an in memory string.
+func NewSource(name, content string) *Source {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	lineStarts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	return &Source{Name: name, Content: content, lineStarts: lineStarts}
+}
+
+// Position converts a byte offset into a line and column.
+func (s *Source) Position(offset int) Position {
+	line := s.lineOf(offset)
+	start := s.lineStarts[line]
+	if offset > len(s.Content) {
+		offset = len(s.Content)
+	}
+	return Position{
+		File:   s.Name,
+		Line:   line + 1,
+		Column: utf8.RuneCountInString(s.Content[start:offset]) + 1,
+	}
+}
+
+// Line returns the text of a line (starting at 1) without its line break.
+func (s *Source) Line(line int) string {
+	if line < 1 || line > len(s.lineStarts) {
+		return ""
+	}
+	start := s.lineStarts[line-1]
+	end := len(s.Content)
+	if line < len(s.lineStarts) {
+		end = s.lineStarts[line] - 1
+	}
+	return s.Content[start:end]
+}
+
+// Errorf builds a Diagnostic pointing to the given byte offset.
+func (s *Source) Errorf(offset int, format string, args ...any) *Diagnostic {
+	pos := s.Position(offset)
+	return &Diagnostic{
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+		Source:  s.Line(pos.Line),
+	}
+}
+
+// Index (starting at 0) of the line that contains the offset.
+func (s *Source) lineOf(offset int) int {
+	low, high := 0, len(s.lineStarts)-1
+	for low < high {
+		mid := (low + high + 1) / 2
+		if s.lineStarts[mid] <= offset {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low
+}
+
+// =====================
+//	  DIAGNOSTICS
+// =====================
+
+// Diagnostic is an error found while reading a source file. It is printed as:
+//
+//	file.lex:3:12: unterminated string
+//	    id   "abc
+//	         ^
+type Diagnostic struct {
+	Pos     Position
+	Message string
+	// Full line where the error was found, used to draw the caret
+	Source string
+}
+
+func (d *Diagnostic) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s", d.Pos, d.Message))
+	if d.Source == "" {
+		return sb.String()
+	}
+
+	// Keep the tabs of the original line so the caret stays aligned
+	caret := make([]rune, 0, d.Pos.Column)
+	for i, r := range []rune(d.Source) {
+		if i >= d.Pos.Column-1 {
+			break
+		}
+		if r == '\t' {
+			caret = append(caret, '\t')
+		} else {
+			caret = append(caret, ' ')
+		}
+	}
+	sb.WriteString("\n\t" + d.Source + "\n\t" + string(caret) + "^")
+	return sb.String()
+}
+
+// Diagnostics groups all the errors found in a single pass over a file.
+type Diagnostics []*Diagnostic
+
+func (d Diagnostics) Error() string {
+	messages := make([]string, 0, len(d))
+	for _, diagnostic := range d {
+		messages = append(messages, diagnostic.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Err returns nil when there are no diagnostics, so it can be returned directly as an error.
+func (d Diagnostics) Err() error {
+	if len(d) == 0 {
+		return nil
+	}
+	return d
+}
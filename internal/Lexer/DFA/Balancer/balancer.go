@@ -37,6 +37,16 @@ func IsBalanced(expression string) (bool, []string) {
 			// Skip for scaped sequences
 			i += 2
 			continue
+		case char == OpenBracket.Symbol:
+			// Classes may contain any symbol, Ex: [(] so they are skipped as a whole
+			end := classEnd(symbols, i)
+			if end == -1 {
+				steps = append(steps, "Clase sin cerrar: "+string(char))
+				return false, steps
+			}
+			steps = append(steps, "Clase: "+string(symbols[i:end]))
+			i = end
+			continue
 		case char == OpenParenthesis.Symbol, char == OpenBracket.Symbol, char == OpenBrace.Symbol:
 			// Identificar el carácter de apertura correspondiente.
 			var c *Character
@@ -67,3 +77,23 @@ func IsBalanced(expression string) (bool, []string) {
 	}
 	return false, steps
 }
+
+// Retorna el índice siguiente al cierre de la clase que inicia en symbols[start], -1 si no se cierra.
+// Las clases POSIX "[:alpha:]" pueden estar anidadas.
+func classEnd(symbols []rune, start int) int {
+	for i := start + 1; i < len(symbols); i++ {
+		switch {
+		case symbols[i] == '\\':
+			i++
+		case symbols[i] == '[' && i+1 < len(symbols) && symbols[i+1] == ':':
+			i += 2
+			for i+1 < len(symbols) && !(symbols[i] == ':' && symbols[i+1] == ']') {
+				i++
+			}
+			i++ // Stay on "]" of ":]"
+		case symbols[i] == ']':
+			return i + 1
+		}
+	}
+	return -1
+}
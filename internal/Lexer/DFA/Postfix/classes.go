@@ -0,0 +1,327 @@
+package postfix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Classes are sets of runes, they can be written as:
+//
+//	[abc] [a-z] [^0-9]  explicit sets, a leading "^" negates the set
+//	\d \w \s            shorthands, the uppercase version negates them (\D \W \S)
+//	[:alpha:]           POSIX classes, only valid within brackets. Ex: [[:alpha:]_]
+//	\p{L} \p{Greek}     unicode categories, scripts and properties, \P{...} negates them
+//
+// Each class is kept as a single symbol, later the DFA splits all of them into
+// disjoint sets so they can be used as transitions.
+
+var shorthandClasses = map[rune][]RuneRange{
+	'd': {{'0', '9'}},
+	'w': {{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}},
+	's': {{'\t', '\n'}, {'\f', '\r'}, {' ', ' '}},
+}
+
+var posixClasses = map[string][]RuneRange{
+	"alnum":  {{'0', '9'}, {'A', 'Z'}, {'a', 'z'}},
+	"alpha":  {{'A', 'Z'}, {'a', 'z'}},
+	"ascii":  {{0, unicode.MaxASCII}},
+	"blank":  {{'\t', '\t'}, {' ', ' '}},
+	"cntrl":  {{0, 0x1F}, {0x7F, 0x7F}},
+	"digit":  {{'0', '9'}},
+	"graph":  {{'!', '~'}},
+	"lower":  {{'a', 'z'}},
+	"print":  {{' ', '~'}},
+	"punct":  {{'!', '/'}, {':', '@'}, {'[', '`'}, {'{', '~'}},
+	"space":  {{'\t', '\r'}, {' ', ' '}},
+	"upper":  {{'A', 'Z'}},
+	"word":   {{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}},
+	"xdigit": {{'0', '9'}, {'A', 'F'}, {'a', 'f'}},
+}
+
+// ClassRanges returns the runes matched by a class like "[a-z]", "\d" or "\p{L}",
+// or an error if the class is malformed.
+func ClassRanges(class string) ([]RuneRange, error) {
+	expresion := []rune(class)
+	if len(expresion) == 0 {
+		return nil, fmt.Errorf("empty class")
+	}
+	ranges, end, err := parseClass(expresion, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	if end != len(expresion) {
+		return nil, fmt.Errorf("unexpected %q after class %s", string(expresion[end:]), string(expresion[:end]))
+	}
+	return ranges, nil
+}
+
+// ClassString returns a canonical representation of a set of ranges. Ex: [0-9A-Z_]
+func ClassString(ranges []RuneRange) string {
+	var sb strings.Builder
+	sb.WriteRune('[')
+	for _, r := range ranges {
+		writeClassRune(&sb, r.Lo)
+		if r.Hi > r.Lo {
+			sb.WriteRune('-')
+			writeClassRune(&sb, r.Hi)
+		}
+	}
+	sb.WriteRune(']')
+	return sb.String()
+}
+
+func writeClassRune(sb *strings.Builder, r rune) {
+	if !unicode.IsPrint(r) || r == ' ' || strings.ContainsRune(`\[]^-`, r) {
+		fmt.Fprintf(sb, `\u{%X}`, r)
+		return
+	}
+	sb.WriteRune(r)
+}
+
+// Checks if "\r" starts a class instead of an escaped character.
+func isClassEscape(r rune) bool {
+	return strings.ContainsRune("dwsDWSpP", r)
+}
+
+// Reads the class that starts at expresion[start], either a bracket class "[...]" or
+// an escaped one "\d", "\p{L}". Returns its sorted ranges and the index after it.
+//
+// If fold is set the class also matches the other cases of its letters, negated
+// classes are folded before negating them so [^a] matches neither "a" nor "A".
+func parseClass(expresion []rune, start int, fold bool) ([]RuneRange, int, error) {
+	if expresion[start] == '\\' {
+		return parseEscapedClass(expresion, start, fold)
+	}
+
+	i := start + 1
+	negated := false
+	if i < len(expresion) && expresion[i] == '^' {
+		negated = true
+		i++
+	}
+
+	ranges := make([]RuneRange, 0)
+	for {
+		if i >= len(expresion) {
+			return nil, 0, fmt.Errorf("unterminated class %s, missing ]", string(expresion[start:]))
+		}
+		if expresion[i] == ']' {
+			break
+		}
+
+		// SUPPORT POSIX CLASSES
+		if expresion[i] == '[' && i+1 < len(expresion) && expresion[i+1] == ':' {
+			end := indexOfRunes(expresion, i+2, ":]")
+			if end == -1 {
+				return nil, 0, fmt.Errorf("unterminated POSIX class, missing :]")
+			}
+			name := string(expresion[i+2 : end])
+			set, exist := posixClasses[name]
+			if !exist {
+				return nil, 0, fmt.Errorf("unknown POSIX class [:%s:]", name)
+			}
+			ranges = append(ranges, set...)
+			i = end + 2
+			continue
+		}
+
+		// SUPPORT SHORTHANDS AND UNICODE CLASSES
+		if expresion[i] == '\\' && i+1 < len(expresion) && isClassEscape(expresion[i+1]) {
+			set, end, err := parseEscapedClass(expresion, i, fold)
+			if err != nil {
+				return nil, 0, err
+			}
+			ranges = append(ranges, set...)
+			i = end
+			continue
+		}
+
+		lo, next, err := classRune(expresion, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		i = next
+
+		// SUPPORT RANGES EXPRESIONS, a "-" at the end is taken literally
+		if i+1 < len(expresion) && expresion[i] == '-' && expresion[i+1] != ']' {
+			hi, next, err := classRune(expresion, i+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			if hi < lo {
+				return nil, 0, fmt.Errorf("invalid range %c-%c, its start is greater than its end", lo, hi)
+			}
+			ranges = append(ranges, RuneRange{Lo: lo, Hi: hi})
+			i = next
+			continue
+		}
+
+		// SUPPORT SINGLE SYMBOLS
+		ranges = append(ranges, RuneRange{Lo: lo, Hi: lo})
+	}
+
+	if len(ranges) == 0 {
+		return nil, 0, fmt.Errorf("empty class %s", string(expresion[start:i+1]))
+	}
+
+	ranges = normalizeRanges(ranges)
+	if fold {
+		ranges = foldRanges(ranges)
+	}
+	if negated {
+		ranges = negateRanges(ranges)
+	}
+	return ranges, i + 1, nil
+}
+
+// Reads a single, maybe escaped, character of a class.
+func classRune(expresion []rune, i int) (rune, int, error) {
+	if expresion[i] != '\\' {
+		return expresion[i], i + 1, nil
+	}
+	if i+1 >= len(expresion) {
+		return 0, 0, fmt.Errorf("escape symbol \\ at the end of a class")
+	}
+	if isClassEscape(expresion[i+1]) {
+		return 0, 0, fmt.Errorf("class \\%c can't be used as the limit of a range", expresion[i+1])
+	}
+	return expresion[i+1], i + 2, nil
+}
+
+// Reads "\d", "\W", "\p{Greek}"... starting on expresion[start] which must be "\".
+func parseEscapedClass(expresion []rune, start int, fold bool) ([]RuneRange, int, error) {
+	if start+1 >= len(expresion) {
+		return nil, 0, fmt.Errorf("escape symbol \\ at the end of the expresion")
+	}
+	kind := expresion[start+1]
+
+	if kind == 'p' || kind == 'P' {
+		if start+2 >= len(expresion) || expresion[start+2] != '{' {
+			return nil, 0, fmt.Errorf("expected a unicode class like \\%c{L}", kind)
+		}
+		end := indexOfRunes(expresion, start+3, "}")
+		if end == -1 {
+			return nil, 0, fmt.Errorf("unterminated unicode class \\%c{, missing }", kind)
+		}
+		name := string(expresion[start+3 : end])
+		table, exist := unicodeTable(name)
+		if !exist {
+			return nil, 0, fmt.Errorf("unknown unicode class \\%c{%s}", kind, name)
+		}
+		ranges := tableRanges(table)
+		if fold {
+			ranges = foldRanges(ranges)
+		}
+		if kind == 'P' {
+			ranges = negateRanges(ranges)
+		}
+		return ranges, end + 1, nil
+	}
+
+	ranges, exist := shorthandClasses[unicode.ToLower(kind)]
+	if !exist {
+		return nil, 0, fmt.Errorf("unknown class \\%c", kind)
+	}
+	ranges = normalizeRanges(ranges)
+	if fold {
+		ranges = foldRanges(ranges)
+	}
+	if unicode.IsUpper(kind) {
+		ranges = negateRanges(ranges)
+	}
+	return ranges, start + 2, nil
+}
+
+// Looks up a unicode category (L, Nd), script (Greek) or property (White_Space).
+func unicodeTable(name string) (*unicode.RangeTable, bool) {
+	if table, exist := unicode.Categories[name]; exist {
+		return table, true
+	}
+	if table, exist := unicode.Scripts[name]; exist {
+		return table, true
+	}
+	table, exist := unicode.Properties[name]
+	return table, exist
+}
+
+// Converts a unicode table into sorted ranges.
+func tableRanges(table *unicode.RangeTable) []RuneRange {
+	ranges := make([]RuneRange, 0, len(table.R16)+len(table.R32))
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			ranges = append(ranges, RuneRange{Lo: lo, Hi: hi})
+			return
+		}
+		for r := lo; r <= hi; r += stride {
+			ranges = append(ranges, RuneRange{Lo: r, Hi: r})
+		}
+	}
+	for _, r := range table.R16 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return normalizeRanges(ranges)
+}
+
+// Sorts a list of ranges merging the ones that overlap or are next to each other.
+func normalizeRanges(ranges []RuneRange) []RuneRange {
+	sorted := append([]RuneRange{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Lo < sorted[j].Lo })
+
+	merged := make([]RuneRange, 0, len(sorted))
+	for _, r := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && r.Lo <= merged[last].Hi+1 {
+			merged[last].Hi = max(merged[last].Hi, r.Hi)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Adds all the cases of the letters within a list of normalized ranges, following
+// the unicode simple folding. Ex: [a-c] => [A-Ca-c]
+func foldRanges(ranges []RuneRange) []RuneRange {
+	folded := append([]RuneRange{}, ranges...)
+	for _, rr := range ranges {
+		for r := rr.Lo; r <= rr.Hi; r++ {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				folded = append(folded, RuneRange{Lo: f, Hi: f})
+			}
+		}
+	}
+	return normalizeRanges(folded)
+}
+
+// Returns all the runes NOT included on a list of normalized ranges.
+func negateRanges(ranges []RuneRange) []RuneRange {
+	negated := make([]RuneRange, 0, len(ranges)+1)
+	next := rune(0)
+	for _, r := range ranges {
+		if r.Lo > next {
+			negated = append(negated, RuneRange{Lo: next, Hi: r.Lo - 1})
+		}
+		next = r.Hi + 1
+	}
+	if next <= unicode.MaxRune {
+		negated = append(negated, RuneRange{Lo: next, Hi: unicode.MaxRune})
+	}
+	return negated
+}
+
+// Index of the first occurrence of target within expresion[from:], -1 if not found.
+func indexOfRunes(expresion []rune, from int, target string) int {
+	if from > len(expresion) {
+		return -1
+	}
+	index := strings.Index(string(expresion[from:]), target)
+	if index == -1 {
+		return -1
+	}
+	return from + len([]rune(string(expresion[from:])[:index]))
+}
@@ -0,0 +1,111 @@
+package postfix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_classRanges(t *testing.T) {
+	cases := []struct {
+		class    string
+		expected string
+	}{
+		{`[a-z]`, `[a-z]`},
+		{`[zyx_a-c]`, `[_a-cx-z]`},
+		{`[a-]`, `[\u{2D}a]`},
+		{`[\]\-]`, `[\u{2D}\u{5D}]`},
+		{`\d`, `[0-9]`},
+		{`[\dA-F]`, `[0-9A-F]`},
+		{`[[:xdigit:]]`, `[0-9A-Fa-f]`},
+		{"[^\x00-\U0010FFFE]", `[\u{10FFFF}]`},
+		{`\S`, `[\u{0}-\u{8}\u{B}\u{E}-\u{1F}!-\u{10FFFF}]`},
+		{`\p{Greek}`, ""},
+	}
+
+	for _, c := range cases {
+		ranges, err := ClassRanges(c.class)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", c.class, err)
+			continue
+		}
+		if c.expected != "" && ClassString(ranges) != c.expected {
+			t.Errorf("expected %s for %s, got %s", c.expected, c.class, ClassString(ranges))
+		}
+		fmt.Println(c.class, len(ranges), "ranges")
+	}
+}
+
+func Test_unicodeClasses(t *testing.T) {
+	letters, _ := ClassRanges(`\p{L}`)
+	notLetters, _ := ClassRanges(`\P{L}`)
+
+	contains := func(ranges []RuneRange, r rune) bool {
+		for _, rr := range ranges {
+			if r >= rr.Lo && r <= rr.Hi {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range "añóΩжあ" {
+		if !contains(letters, r) || contains(notLetters, r) {
+			t.Errorf("%c should be a letter", r)
+		}
+	}
+	for _, r := range "1 _-" {
+		if contains(letters, r) || !contains(notLetters, r) {
+			t.Errorf("%c should not be a letter", r)
+		}
+	}
+}
+
+func Test_classErrors(t *testing.T) {
+	cases := []struct {
+		class   string
+		message string
+	}{
+		{`[a-z`, "unterminated class"},
+		{`[]`, "empty class"},
+		{`[z-a]`, "invalid range"},
+		{`[[:letters:]]`, "unknown POSIX class"},
+		{`\p{Klingon}`, "unknown unicode class"},
+		{`\pL`, "expected a unicode class"},
+		{`[a-\d]`, "limit of a range"},
+	}
+
+	for _, c := range cases {
+		_, err := ClassRanges(c.class)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("expected %q for %s, got %v", c.message, c.class, err)
+		}
+	}
+}
+
+func Test_caselessSymbols(t *testing.T) {
+	raw := func(pattern string) []RawSymbol {
+		symbols := make([]RawSymbol, 0)
+		for _, r := range pattern {
+			symbols = append(symbols, RawSymbol{Value: string(r), Fold: true})
+		}
+		return symbols
+	}
+
+	symbols, err := convertToSymbols(raw(`k1[^a-c]\d`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"[Kk\u212A]", "", "[\\u{0}-@D-`d-\\u{10FFFF}]", "[0-9]"}
+	for i, symbol := range symbols {
+		if expected[i] == "" {
+			if symbol.Ranges != nil || symbol.Value != "1" {
+				t.Errorf("expected 1 to be kept as it is, got %v", symbol)
+			}
+			continue
+		}
+		if ClassString(symbol.Ranges) != expected[i] || !strings.HasPrefix(symbol.Value, CASELESS_PREFIX) {
+			t.Errorf("expected %s, got %s %s", expected[i], symbol.Value, ClassString(symbol.Ranges))
+		}
+	}
+}
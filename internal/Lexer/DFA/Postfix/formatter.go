@@ -1,5 +1,7 @@
 package postfix
 
+import "unicode"
+
 // This file contains logic specifically to manipulating a list of symbols
 // or strings
 
@@ -7,18 +9,40 @@ package postfix
 func convertToSymbols(expresion []RawSymbol) ([]Symbol, error) {
 	finalSymbols := make([]Symbol, 0, len(expresion))
 
+	// Classes are read directly from the runes, each raw symbol holds one.
+	runes := make([]rune, len(expresion))
+	for i, symbol := range expresion {
+		runes[i] = []rune(symbol.Value + " ")[0]
+	}
+
 	for i := 0; i < len(expresion); {
 		t1, _ := getRawSymbolInfo(expresion, i)
 		t2, t2Exist := getRawSymbolInfo(expresion, i+1)
 
+		// SUPPORT CLASSES: [a-z], \d, \p{L}
+		if t1.Value == "[" || (t1.Value == ESCAPE_SYMBOL && t2Exist && isClassEscape(runes[i+1])) {
+			ranges, end, err := parseClass(runes, i, t1.Fold)
+			if err != nil {
+				return nil, err
+			}
+			value := string(runes[i:end])
+			if t1.Fold {
+				value = CASELESS_PREFIX + value // A caseless class stands for other runes
+			}
+			finalSymbols = append(finalSymbols, Symbol{
+				Value:      value,
+				Precedence: 60,
+				IsOperator: false,
+				Action:     Action{Priority: NULL_ACTION_PRIORITY},
+				Ranges:     ranges,
+			})
+			i = end
+			continue
+		}
+
 		if t1.Value == ESCAPE_SYMBOL {
 			if t2Exist {
-				finalSymbols = append(finalSymbols, Symbol{
-					Value:      t2.Value,
-					Precedence: 60,
-					IsOperator: false,
-					Action:     Action{Priority: t2.Action.Priority},
-				})
+				finalSymbols = append(finalSymbols, characterSymbol(t2.Value, Action{Priority: t2.Action.Priority}, t2.Fold))
 
 				i += 2
 				continue
@@ -27,12 +51,7 @@ func convertToSymbols(expresion []RawSymbol) ([]Symbol, error) {
 		if operator, isOperator := OPERATORS[t1.Value]; isOperator {
 			finalSymbols = append(finalSymbols, operator)
 		} else {
-			finalSymbols = append(finalSymbols, Symbol{
-				Value:      t1.Value,
-				Precedence: 60,
-				IsOperator: false,
-				Action:     t1.Action,
-			})
+			finalSymbols = append(finalSymbols, characterSymbol(t1.Value, t1.Action, t1.Fold))
 		}
 		i++
 	}
@@ -40,6 +59,25 @@ func convertToSymbols(expresion []RawSymbol) ([]Symbol, error) {
 	return finalSymbols, nil
 }
 
+// Creates the symbol of a plain character. If fold is set and the character is a
+// letter, it becomes a class with all its cases. Ex: "k" => [Kk\u{212A}]
+func characterSymbol(value string, action Action, fold bool) Symbol {
+	symbol := Symbol{
+		Value:      value,
+		Precedence: 60,
+		IsOperator: false,
+		Action:     action,
+	}
+
+	runes := []rune(value)
+	if !fold || len(runes) != 1 || unicode.SimpleFold(runes[0]) == runes[0] {
+		return symbol
+	}
+	symbol.Value = CASELESS_PREFIX + value
+	symbol.Ranges = foldRanges([]RuneRange{{Lo: runes[0], Hi: runes[0]}})
+	return symbol
+}
+
 // Add concatenation symbol to an expresion.
 func addConcatenationSymbols(expresion []Symbol) ([]Symbol, error) {
 
@@ -49,24 +87,6 @@ func addConcatenationSymbols(expresion []Symbol) ([]Symbol, error) {
 		s1, _ := getSymbolInfo(expresion, i)
 		s2, s2Exist := getSymbolInfo(expresion, i+1)
 
-		// SPECIAL CASE, if Class sctructure encontared skip([abc])
-		if s1.Value == "[" && s1.IsOperator {
-			newIndex := i
-			// Search for the closing class bracket "]"
-			for ; newIndex < len(expresion); newIndex++ {
-				step, _ := getSymbolInfo(expresion, newIndex)
-
-				if step.Value == "]" && step.IsOperator {
-					break
-				}
-
-				formattedTokens = append(formattedTokens, step)
-			}
-
-			i = newIndex // To start with the next symbol after the class
-			continue
-		}
-
 		formattedTokens = append(formattedTokens, s1)
 
 		if s2Exist && shouldAddConcatenationSymbol(s1, s2) {
@@ -94,6 +114,11 @@ func shouldAddConcatenationSymbol(s1, s2 Symbol) bool {
 		return false
 	}
 
+	// A prefix operator goes right before its operand
+	if s1.IsPrefix() {
+		return false
+	}
+
 	// If the S1 is Operator :
 	// 	need more than 1 operands, or
 	// 	is an open parenthesis, or
@@ -105,10 +130,8 @@ func shouldAddConcatenationSymbol(s1, s2 Symbol) bool {
 			return false
 		}
 	}
-	// 	If S2 is an "(" or "[" operator
-	if s2.IsOperator &&
-		((s2.Value == "(") ||
-			(s2.Value == "[")) {
+	// 	If S2 is an "(" or a prefix operator
+	if s2.IsOperator && (s2.Value == "(" || s2.IsPrefix()) {
 		return true
 	}
 	if s2.IsOperator { // If s2 is not operand then
@@ -54,6 +54,9 @@ func shuntingyard(tokens []Symbol) []Symbol {
 				postfix = append(postfix, stack.Pop().(Symbol))
 			}
 			stack.Pop()
+		} else if token.IsPrefix() {
+			// Its operand is not read yet, nothing before it can be popped
+			stack.Push(token)
 		} else {
 			for stack.Len() > 0 {
 				peekedChar := stack.Peek().(Symbol)
@@ -0,0 +1,27 @@
+package postfix
+
+import "testing"
+
+func Test_languageOperators(t *testing.T) {
+	cases := map[string]string{
+		"a~b*c":   "ab*~·c·",
+		"a|b-c&d": "abc-d&|",
+		"~~(ab)":  "ab·~~",
+		"(a)~b":   "ab~·",
+		`a\-b`:    "a-·b·", // An escaped - is a character
+	}
+
+	for expresion, expected := range cases {
+		raw := make([]RawSymbol, 0)
+		for _, r := range expresion {
+			raw = append(raw, RawSymbol{Value: string(r), Action: Action{Priority: NULL_ACTION_PRIORITY}})
+		}
+		result, _, err := RegexToPostfix(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != expected {
+			t.Errorf("%s: expected %s, got %s", expresion, expected, result)
+		}
+	}
+}
@@ -1,9 +1,6 @@
 package postfix
 
-import (
-	"slices"
-	"sort"
-)
+import "slices"
 
 // The original Regex definition contains a small set of operators,
 // This file provide functions to translate from "non-primitive" operators to primitive.
@@ -37,14 +34,6 @@ func convertToPrimitiveOperators(expresion []Symbol) []Symbol {
 
 			i -= (end - start) + 1
 			continue
-			// INTERCHANGE CLASSES
-		} else if s1.IsOperator && s1.Value == "]" {
-			start, end := getSubExpresionIndex(expresion, i, "[", "]")
-			subExpresion := interchangeClasses(expresion[start+1 : end-1]) // Pass without brackets
-			formattedSymbols = append(formattedSymbols, subExpresion...)
-
-			i -= (end - start)
-			continue
 		}
 
 		// If any condition raises, just append the caracter
@@ -148,79 +137,3 @@ func interchangePositiveLock(expresion []Symbol) []Symbol {
 	)
 	return formattedSymbols
 }
-
-// Expands a regex-like set "A-Db-j1-3" into a unique sorted character set
-// returns: "ABCDbdefgj123"
-//
-// NOTE: The open-close brackets "[]" for the class must not be passed.
-func interchangeClasses(expresion []Symbol) []Symbol {
-	var resultSet map[rune]struct{} = make(map[rune]struct{})
-
-	for i := 0; i < len(expresion); {
-		s1, _ := getSymbolInfo(expresion, i)
-		s2, s2Exist := getSymbolInfo(expresion, i+1)
-		s3, s3Exist := getSymbolInfo(expresion, i+2)
-
-		// SUPPORT ESCAPED SYMBOLS
-		if s1.Value == ESCAPE_SYMBOL && s2Exist {
-			r := []rune(s2.Value)[0]
-			resultSet[r] = struct{}{}
-			i += 2
-			continue
-			// SUPPORT RANGES EXPRESIONS
-		} else if s2Exist && s3Exist && s2.Value == "-" {
-			// Handle range expansion
-			start := []rune(s1.Value)[0]
-			end := []rune(s3.Value)[0]
-			for _, r := range expandRange(start, end) {
-				resultSet[r] = struct{}{}
-			}
-			i += 3
-			continue
-		}
-
-		// SUPPORT SINGLE SYMBOLS
-		r := []rune(expresion[i].Value)[0]
-		resultSet[r] = struct{}{}
-		i++
-	}
-
-	// Convert map keys to sorted slice
-	var resultSlice []rune
-	for k := range resultSet {
-		resultSlice = append(resultSlice, k)
-	}
-	sort.Slice(resultSlice, func(i, j int) bool { return resultSlice[i] > resultSlice[j] })
-
-	// Convert to a list of Symbols with "|" in between
-	var finalExpression []Symbol
-	finalExpression = append(finalExpression, OPERATORS[")"])
-	for i, r := range resultSlice {
-		finalExpression = append(finalExpression,
-			Symbol{Value: string(r),
-				IsOperator: false,
-				Precedence: 60,
-				Action:     Action{Priority: -1}})
-		if i < len(resultSlice)-1 { // Avoid adding "|" at the end
-			finalExpression = append(finalExpression, OPERATORS["|"])
-		}
-	}
-	finalExpression = append(finalExpression, OPERATORS["("])
-
-	return finalExpression
-}
-
-// Expands a range like "A-D" into "ABCD"
-func expandRange(start, end rune) []rune {
-	if start > end {
-		start, end = end, start // Ensure correct order
-	}
-	size := int(end-start) + 1
-	result := make([]rune, size)
-
-	for i, r := 0, start; r <= end; i, r = i+1, r+1 {
-		result[i] = r
-	}
-
-	return result
-}
@@ -12,6 +12,8 @@ type Action struct {
 type RawSymbol struct {
 	Value  string
 	Action Action
+	// Match letters regardless of their case. Ex: "k" also matches "K" and "K" (Kelvin sign)
+	Fold bool
 }
 
 // Representation of a string that can be used for processing regex patterns.
@@ -28,22 +30,50 @@ type Symbol struct {
 
 	// Number of Operands
 	Operands int
+
+	// Only for classes ([a-z], \d, \p{L}), the runes the symbol stands for.
+	Ranges []RuneRange
+}
+
+// Inclusive range of runes. Ex: a-z => {Lo: 'a', Hi: 'z'}
+type RuneRange struct {
+	Lo, Hi rune
 }
 
 func (s *Symbol) String() string {
 	return s.Value
 }
 
+// Checks if the symbol is an operator written before its operand, like "~".
+func (s Symbol) IsPrefix() bool {
+	return s.IsOperator && s.Value == COMPLEMENT_SYMBOL
+}
+
 const ESCAPE_SYMBOL string = "\\"
+
+// Added to the value of caseless symbols so they are not mixed up with the
+// case sensitive ones. Ex: "(?i)a" => [Aa]
+const CASELESS_PREFIX string = "(?i)"
 const CONCAT_SYMBOL string = "·"
 
+// Operators on whole languages. They are not built by the direct method, each
+// operation is built as its own automaton (see dfa.buildOperations). Ex:
+//
+//	{id} - (if|else)   identifiers that are not keywords
+//	[a-z]+ & (..)+     lowercase words of even length
+//	~(.*"*/".*)        any text that doesn't contain */
+const INTERSECTION_SYMBOL string = "&"
+const DIFFERENCE_SYMBOL string = "-"
+const COMPLEMENT_SYMBOL string = "~"
+
 var OPERATORS = map[string]Symbol{
 	")": {Value: ")", Precedence: 10, IsOperator: true, Operands: 1, Action: Action{Priority: NULL_ACTION_PRIORITY}},
 	"(": {Value: "(", Precedence: 10, IsOperator: true, Operands: 0, Action: Action{Priority: NULL_ACTION_PRIORITY}},
-	"]": {Value: "]", Precedence: 10, IsOperator: true, Operands: 1, Action: Action{Priority: NULL_ACTION_PRIORITY}},
-	"[": {Value: "[", Precedence: 10, IsOperator: true, Operands: 0, Action: Action{Priority: NULL_ACTION_PRIORITY}},
 	"|": {Value: "|", Precedence: 20, IsOperator: true, Operands: 2, Action: Action{Priority: NULL_ACTION_PRIORITY}},
+	"&": {Value: "&", Precedence: 25, IsOperator: true, Operands: 2, Action: Action{Priority: NULL_ACTION_PRIORITY}},
+	"-": {Value: "-", Precedence: 25, IsOperator: true, Operands: 2, Action: Action{Priority: NULL_ACTION_PRIORITY}},
 	"·": {Value: "·", Precedence: 30, IsOperator: true, Operands: 2, Action: Action{Priority: NULL_ACTION_PRIORITY}},
+	"~": {Value: "~", Precedence: 35, IsOperator: true, Operands: 1, Action: Action{Priority: NULL_ACTION_PRIORITY}}, // Prefix
 	"?": {Value: "?", Precedence: 40, IsOperator: true, Operands: 1, Action: Action{Priority: NULL_ACTION_PRIORITY}},
 	"*": {Value: "*", Precedence: 40, IsOperator: true, Operands: 1, Action: Action{Priority: NULL_ACTION_PRIORITY}},
 	"+": {Value: "+", Precedence: 40, IsOperator: true, Operands: 1, Action: Action{Priority: NULL_ACTION_PRIORITY}},
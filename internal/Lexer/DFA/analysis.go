@@ -0,0 +1,211 @@
+package dfa
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// Problems the rules of a lexer may have, found by looking at the DFA built from them.
+type RuleProblem int
+
+const (
+	SHADOWED_RULE     RuleProblem = iota // The rule never wins, other rules always match its lexemes first
+	EMPTY_RULE                           // The rule matches the empty string
+	OVERLAPPING_RULES                    // Two rules match the same lexeme, the first one wins
+)
+
+type RuleWarning struct {
+	Problem RuleProblem
+	Rule    int    // Priority of the rule with the problem
+	Other   int    // Rule that wins over it or overlaps with it, -1 if there is none
+	Example string // Lexeme that shows the problem
+}
+
+// AnalyzeRules looks for rules that can never produce a token, rules that match
+// the empty string and pairs of rules that match a same lexeme.
+//
+// Each rule is recognized by its priority (0 for the first rule). A state accepts
+// every rule listed on its actions, but only the first one (highest priority) wins.
+// Examples are the shortest lexemes that reach the state showing the problem.
+func AnalyzeRules(automata *DFA, numRules int) []RuleWarning {
+	warnings := make([]RuleWarning, 0)
+	states, examples := shortestLexemes(automata)
+
+	// Rules that accept an empty lexeme
+	for _, action := range automata.StartState.Actions {
+		warnings = append(warnings, RuleWarning{Problem: EMPTY_RULE, Rule: action.Priority, Other: -1})
+	}
+
+	wins := make(map[int]bool)
+	firstAccept := make(map[int]*State) // Shortest state where each rule is accepted
+	overlaps := make(map[[2]int]bool)
+	for _, state := range states {
+		if len(state.Actions) == 0 {
+			continue
+		}
+		wins[state.Actions[0].Priority] = true
+
+		for i, action := range state.Actions {
+			if _, exist := firstAccept[action.Priority]; !exist {
+				firstAccept[action.Priority] = state
+			}
+			for _, other := range state.Actions[i+1:] {
+				pair := [2]int{action.Priority, other.Priority}
+				if pair[0] == pair[1] || overlaps[pair] {
+					continue
+				}
+				overlaps[pair] = true
+				warnings = append(warnings, RuleWarning{
+					Problem: OVERLAPPING_RULES,
+					Rule:    other.Priority,
+					Other:   action.Priority,
+					Example: examples[state],
+				})
+			}
+		}
+	}
+
+	for rule := 0; rule < numRules; rule++ {
+		if wins[rule] {
+			continue
+		}
+		warning := RuleWarning{Problem: SHADOWED_RULE, Rule: rule, Other: -1}
+		if state, exist := firstAccept[rule]; exist {
+			warning.Other = state.Actions[0].Priority
+			warning.Example = examples[state]
+		}
+		warnings = append(warnings, warning)
+	}
+
+	// A shadowed rule overlaps with the rule that wins, no need to say it twice
+	filtered := warnings[:0]
+	for _, warning := range warnings {
+		if warning.Problem != OVERLAPPING_RULES || wins[warning.Rule] {
+			filtered = append(filtered, warning)
+		}
+	}
+	warnings = filtered
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].Problem != warnings[j].Problem {
+			return warnings[i].Problem < warnings[j].Problem
+		}
+		return warnings[i].Rule < warnings[j].Rule
+	})
+	return warnings
+}
+
+// Walks the DFA breadth first, returning the reachable states in the order they
+// were found alongside the shortest lexeme that reaches each one of them.
+func shortestLexemes(automata *DFA) ([]*State, map[*State]string) {
+	examples := map[*State]string{automata.StartState: ""}
+	order := []*State{automata.StartState}
+
+	for i := 0; i < len(order); i++ {
+		state := order[i]
+
+		symbols := make([]Symbol, 0, len(state.Transitions))
+		for symbol := range state.Transitions {
+			if !automata.IsMarker(symbol) {
+				symbols = append(symbols, symbol)
+			}
+		}
+		sort.Strings(symbols)
+
+		for _, symbol := range symbols {
+			next := state.Transitions[symbol]
+			if _, visited := examples[next]; visited {
+				continue
+			}
+			examples[next] = examples[state] + automata.sampleRune(symbol)
+			order = append(order, next)
+		}
+	}
+
+	return order, examples
+}
+
+// IsMarker checks if a transition symbol is the special symbol that marks the end
+// of a rule, instead of a character or a class.
+func (automata *DFA) IsMarker(symbol Symbol) bool {
+	_, isClass := automata.Classes[symbol]
+	return !isClass && utf8.RuneCountInString(symbol) > 1
+}
+
+// Match runs the DFA over a whole lexeme, returning the rule that wins when the
+// lexeme is read entirely, false if no rule accepts it.
+func (automata *DFA) Match(lexeme string) (int, bool) {
+	state := automata.StartState
+	for _, r := range lexeme {
+		next := automata.step(state, r)
+		if next == nil {
+			return 0, false
+		}
+		state = next
+	}
+	if len(state.Actions) == 0 {
+		return 0, false
+	}
+	return state.Actions[0].Priority, true
+}
+
+// Returns the state reached from another one with a rune, nil if there is none.
+func (automata *DFA) step(state *State, r rune) *State {
+	if next, exist := state.Transitions[string(r)]; exist {
+		return next
+	}
+	for symbol, next := range state.Transitions {
+		for _, interval := range automata.Classes[symbol] {
+			if interval.Lo <= r && r <= interval.Hi {
+				return next
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the runes matched by a transition symbol.
+func (automata *DFA) symbolRanges(symbol Symbol) []postfix.RuneRange {
+	if ranges, isClass := automata.Classes[symbol]; isClass {
+		return ranges
+	}
+	r, _ := utf8.DecodeRuneInString(symbol)
+	return []postfix.RuneRange{{Lo: r, Hi: r}}
+}
+
+// Returns a rune matched by a transition symbol, visible characters are preferred.
+func (automata *DFA) sampleRune(symbol Symbol) string {
+	ranges, isClass := automata.Classes[symbol]
+	if !isClass {
+		return symbol
+	}
+	for _, r := range ranges {
+		for c := r.Lo; c <= r.Hi && c < r.Lo+128; c++ {
+			if unicode.IsGraphic(c) && !unicode.IsSpace(c) {
+				return string(c)
+			}
+		}
+	}
+	return string(ranges[0].Lo)
+}
+
+// Describes the warning using the patterns of the rules. Ex:
+//
+//	rule "let" never matches, {id} always wins. Ex: "let"
+func (w RuleWarning) Describe(patterns []string) string {
+	switch w.Problem {
+	case SHADOWED_RULE:
+		if w.Other == -1 {
+			return fmt.Sprintf("rule %s never matches anything", patterns[w.Rule])
+		}
+		return fmt.Sprintf("rule %s never matches, %s always wins. Ex: %q", patterns[w.Rule], patterns[w.Other], w.Example)
+	case EMPTY_RULE:
+		return fmt.Sprintf("rule %s matches the empty string", patterns[w.Rule])
+	default:
+		return fmt.Sprintf("rule %s overlaps with %s, which wins. Ex: %q", patterns[w.Rule], patterns[w.Other], w.Example)
+	}
+}
@@ -0,0 +1,118 @@
+package dfa
+
+import (
+	"strconv"
+	"testing"
+
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// Joins the patterns on a single expresion the same way the lexer generator does.
+func buildRules(t *testing.T, patterns ...string) *DFA {
+	raw := make([]postfix.RawSymbol, 0)
+	for i, pattern := range patterns {
+		if i > 0 {
+			raw = append(raw, postfix.RawSymbol{Value: "|", Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}})
+		}
+		raw = append(raw, postfix.RawSymbol{Value: "(", Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}})
+		for _, r := range pattern {
+			raw = append(raw, postfix.RawSymbol{Value: string(r), Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}})
+		}
+		raw = append(raw,
+			postfix.RawSymbol{Value: ")", Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}},
+			postfix.RawSymbol{Value: strconv.Itoa(i + 10), Action: postfix.Action{Priority: i, Code: "{ return " + strconv.Itoa(i) + " }"}},
+		)
+	}
+
+	automata, _, err := NewDFA(raw, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return automata
+}
+
+func Test_analyzeRules(t *testing.T) {
+	patterns := []string{"[a-z]+", "let", "\\d*", "[0-9a-f]+", "[+]"}
+	automata := buildRules(t, patterns...)
+
+	expected := []RuleWarning{
+		{Problem: SHADOWED_RULE, Rule: 1, Other: 0, Example: "let"},
+		{Problem: EMPTY_RULE, Rule: 2, Other: -1},
+		{Problem: OVERLAPPING_RULES, Rule: 3, Other: 2, Example: "0"},
+		{Problem: OVERLAPPING_RULES, Rule: 3, Other: 0, Example: "a"},
+	}
+
+	warnings := AnalyzeRules(automata, len(patterns))
+	for _, w := range warnings {
+		t.Log(w.Describe(patterns))
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, warnings)
+	}
+	for i := range expected {
+		if warnings[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], warnings[i])
+		}
+	}
+}
+
+func Test_match(t *testing.T) {
+	automata := buildRules(t, "if", "[a-zñ]+", "[0-9]+")
+
+	cases := []struct {
+		lexeme string
+		rule   int
+		ok     bool
+	}{
+		{"if", 0, true},
+		{"i", 1, true},
+		{"ifs", 1, true},
+		{"año", 1, true},
+		{"42", 2, true},
+		{"4a", 0, false},
+		{"", 0, false},
+	}
+	for _, c := range cases {
+		rule, ok := automata.Match(c.lexeme)
+		if ok != c.ok || (ok && rule != c.rule) {
+			t.Errorf("%q: expected rule %d (%t), got %d (%t)", c.lexeme, c.rule, c.ok, rule, ok)
+		}
+	}
+}
+
+func Test_languageOperators(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		accepted []string
+		rejected []string
+	}{
+		{`[a-z]+-(if|else)`, []string{"iff", "i", "els", "elses"}, []string{"if", "else", ""}},
+		{`[a-z]+&([a-z][a-z])+`, []string{"ab", "abcd"}, []string{"a", "abc", "12"}},
+		{`~a`, []string{"", "b", "aa", "ñ", "\n"}, []string{"a"}},
+		{`\/\*~([\s\S]*\*\/[\s\S]*)\*\/`, []string{"/* a */", "/**/", "/* * / */"}, []string{"/* a */ b */", "/*/"}},
+		{`x(~(ab))y&x[a-z]*y`, []string{"xy", "xaay", "xabay"}, []string{"xaby", "x1y"}},
+	}
+
+	for _, c := range cases {
+		automata := buildRules(t, c.pattern)
+		for _, lexeme := range c.accepted {
+			if _, ok := automata.Match(lexeme); !ok {
+				t.Errorf("%s: expected %q to be accepted", c.pattern, lexeme)
+			}
+		}
+		for _, lexeme := range c.rejected {
+			if _, ok := automata.Match(lexeme); ok {
+				t.Errorf("%s: expected %q to be rejected", c.pattern, lexeme)
+			}
+		}
+	}
+
+	// Priorities work as with any other rule
+	automata := buildRules(t, "if", "[a-z]+-if")
+	if rule, _ := automata.Match("if"); rule != 0 {
+		t.Errorf("expected rule 0 for if, got %d", rule)
+	}
+	if rule, _ := automata.Match("iff"); rule != 1 {
+		t.Errorf("expected rule 1 for iff, got %d", rule)
+	}
+}
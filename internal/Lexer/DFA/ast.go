@@ -45,8 +45,9 @@ func BuildAST(postfixSymbols []postfix.Symbol) node {
 				operands[i], operands[j] = operands[j], operands[i]
 			}
 			// Crear un nodo operador con los operandos
+			// Los operadores usan ids negativos, saltando EPSILON_SYMBOL_ID
 			node := node{
-				Id:         -i,
+				Id:         -i - 1,
 				Value:      symbol.Value,
 				Operands:   symbol.Operands,
 				Children:   operands,
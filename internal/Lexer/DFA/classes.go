@@ -0,0 +1,168 @@
+package dfa
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// Max length of a class name before it is shortened. Ex: [a-zA-Z…]
+const MAX_CLASS_NAME = 24
+
+// Symbols the DFA can transition with. Classes of the expresion may overlap ([a-z]
+// and [aeiou]), so they are split into disjoint sets of runes where each rune
+// belongs to a single symbol:
+//
+//	[a-z], [aeiou], x => a, e, i, o, u, x, [b-df-hj-np-tv-wyz]
+//
+// Sets of just one rune are named by the rune itself, so plain characters keep
+// working as they always did.
+type alphabet struct {
+	symbols []Symbol                       // Every transition symbol, including action markers
+	classes map[Symbol][]postfix.RuneRange // Symbols that stand for more than one rune
+	members map[string]map[Symbol]struct{} // Symbols contained by each character or class of the expresion
+}
+
+func newAlphabet(expresion []postfix.Symbol) *alphabet {
+	a := &alphabet{
+		symbols: make([]Symbol, 0),
+		classes: make(map[Symbol][]postfix.RuneRange),
+		members: make(map[string]map[Symbol]struct{}),
+	}
+
+	// Collect the runes each character or class stands for
+	sets := make(map[string][]postfix.RuneRange)
+	markers := make(map[string]struct{})
+	for _, symbol := range expresion {
+		if symbol.IsPrefix() {
+			sets[UNIVERSE_SET] = []postfix.RuneRange{{Lo: 0, Hi: unicode.MaxRune}}
+		}
+		if symbol.IsOperator || symbol.Value == "ε" {
+			continue
+		}
+		if len(symbol.Ranges) > 0 {
+			sets[symbol.Value] = symbol.Ranges
+		} else if utf8.RuneCountInString(symbol.Value) == 1 {
+			r, _ := utf8.DecodeRuneInString(symbol.Value)
+			sets[symbol.Value] = []postfix.RuneRange{{Lo: r, Hi: r}}
+		} else {
+			markers[symbol.Value] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(sets))
+	for name := range sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Split the runes on intervals, where no set starts or ends within an interval.
+	boundaries := make([]rune, 0)
+	for _, name := range names {
+		for _, r := range sets[name] {
+			boundaries = append(boundaries, r.Lo, r.Hi+1)
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+	boundaries = uniqueRunes(boundaries)
+
+	// For each interval, the sets that contain it
+	owners := make([][]int, len(boundaries))
+	for i, name := range names {
+		for _, r := range sets[name] {
+			k := sort.Search(len(boundaries), func(k int) bool { return boundaries[k] >= r.Lo })
+			for ; k < len(boundaries) && boundaries[k] <= r.Hi; k++ {
+				owners[k] = append(owners[k], i)
+			}
+		}
+	}
+
+	// Intervals owned by the same sets form a symbol
+	groups := make(map[string][]postfix.RuneRange)
+	groupOwners := make(map[string][]int)
+	order := make([]string, 0)
+	for k, owner := range owners {
+		if len(owner) == 0 {
+			continue
+		}
+		key := fmt.Sprint(owner)
+		if _, exist := groups[key]; !exist {
+			order = append(order, key)
+			groupOwners[key] = owner
+		}
+		interval := postfix.RuneRange{Lo: boundaries[k], Hi: boundaries[k+1] - 1}
+		last := len(groups[key]) - 1
+		if last >= 0 && groups[key][last].Hi+1 == interval.Lo {
+			groups[key][last].Hi = interval.Hi
+		} else {
+			groups[key] = append(groups[key], interval)
+		}
+	}
+
+	for _, key := range order {
+		ranges := groups[key]
+		symbol := classSymbol(ranges)
+		if len(ranges) > 1 || ranges[0].Lo != ranges[0].Hi {
+			a.classes[symbol] = ranges
+		}
+		a.symbols = append(a.symbols, symbol)
+
+		for _, i := range groupOwners[key] {
+			if a.members[names[i]] == nil {
+				a.members[names[i]] = make(map[Symbol]struct{})
+			}
+			a.members[names[i]][symbol] = struct{}{}
+		}
+	}
+
+	sortedMarkers := make([]string, 0, len(markers))
+	for marker := range markers {
+		sortedMarkers = append(sortedMarkers, marker)
+	}
+	sort.Strings(sortedMarkers)
+	a.symbols = append(a.symbols, sortedMarkers...)
+
+	return a
+}
+
+// Checks if a symbol marks the end of a rule instead of standing for runes.
+func (a *alphabet) isMarker(symbol Symbol) bool {
+	_, isClass := a.classes[symbol]
+	return !isClass && utf8.RuneCountInString(symbol) > 1
+}
+
+// Checks if a character or class of the expresion can transition with a symbol.
+func (a *alphabet) matches(value string, symbol Symbol) bool {
+	if members, isSet := a.members[value]; isSet {
+		_, exist := members[symbol]
+		return exist
+	}
+	return value == symbol
+}
+
+// Names a set of runes. Since sets are disjoint, their first range is enough to
+// tell them apart when the name gets too long.
+func classSymbol(ranges []postfix.RuneRange) Symbol {
+	if len(ranges) == 1 && ranges[0].Lo == ranges[0].Hi {
+		return string(ranges[0].Lo)
+	}
+	name := postfix.ClassString(ranges)
+	if utf8.RuneCountInString(name) > MAX_CLASS_NAME {
+		name = postfix.ClassString(ranges[:1])
+		name = name[:len(name)-1] + "…]"
+	}
+	return name
+}
+
+func uniqueRunes(sorted []rune) []rune {
+	result := sorted[:0]
+	for i, r := range sorted {
+		if i == 0 || r != sorted[i-1] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package dfa
+
+import (
+	"testing"
+
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+func Test_alphabet(t *testing.T) {
+	class := func(text string) postfix.Symbol {
+		ranges, err := postfix.ClassRanges(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return postfix.Symbol{Value: text, Ranges: ranges}
+	}
+
+	expresion := []postfix.Symbol{
+		class("[a-z]"),
+		class("[aeiou]"),
+		{Value: "x"},
+		{Value: "|", IsOperator: true},
+		{Value: "10"},
+	}
+	symbols := newAlphabet(expresion)
+
+	// Vowels are always matched by the same classes, so they share a symbol
+	expected := []Symbol{"[aeiou]", "[b-df-hj-np-tv-wy-z]", "x", "10"}
+	if len(symbols.symbols) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, symbols.symbols)
+	}
+	for _, symbol := range expected {
+		if !contains(symbols.symbols, symbol) {
+			t.Errorf("missing symbol %s on %v", symbol, symbols.symbols)
+		}
+	}
+
+	if !symbols.matches("[a-z]", "[b-df-hj-np-tv-wy-z]") || !symbols.matches("[a-z]", "x") ||
+		symbols.matches("[aeiou]", "x") || !symbols.matches("10", "10") {
+		t.Errorf("unexpected members %v", symbols.members)
+	}
+	if len(symbols.classes) != 2 {
+		t.Errorf("expected 2 class symbols, got %v", symbols.classes)
+	}
+}
+
+func contains(symbols []Symbol, symbol Symbol) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
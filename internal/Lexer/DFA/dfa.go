@@ -58,44 +58,25 @@ func NewDFA(rawExpresion []postfix.RawSymbol, showLogs bool, renderDiagrams bool
 	// runtime.Breakpoint()
 
 	// Generate DFA with direct method
-	finalSymbols := findFinalSymbols(postfixExpr)
+	symbols := newAlphabet(postfixExpr)
+	nextId := len(postfixExpr) + 1
+	buildOperations(&rootNode, symbols, &nextId)
 	positionTable := make(map[int]positionTableRow)
 	_, firstPost, _ := getNodePosition(&rootNode, positionTable)
 	setFollowPos(&rootNode, positionTable)
 
 	// Simplify DFA
-	intermediateStates := simplifyStates(finalSymbols, firstPost, positionTable)
+	intermediateStates := simplifyStates(symbols, firstPost, positionTable)
 	if showLogs {
 		printPositionTable(positionTable)
-		printStateSetTable(intermediateStates, finalSymbols)
+		printStateSetTable(intermediateStates, symbols.symbols)
 	}
 
 	// Build DFA
-	dfa := convertToDFA(intermediateStates, finalSymbols)
+	dfa := convertToDFA(intermediateStates, symbols.symbols)
+	dfa.Classes = symbols.classes
 
-	return dfa, len(finalSymbols), nil
-}
-
-// Return a list with all different final symbols (Not operators) from an expresion.
-func findFinalSymbols(expresion []postfix.Symbol) []string {
-	symbolsSet := make(map[postfix.Symbol]bool)
-
-	for _, symbol := range expresion {
-		if symbol.IsOperator || symbol.Value == "ε" {
-			continue
-		}
-		if _, exist := symbolsSet[symbol]; !exist {
-			symbolsSet[symbol] = true
-		}
-	}
-
-	symbols := make([]string, 0, len(symbolsSet))
-
-	for symbol := range symbolsSet {
-		symbols = append(symbols, symbol.Value)
-	}
-
-	return symbols
+	return dfa, len(symbols.symbols), nil
 }
 
 //==================================
@@ -112,6 +93,21 @@ func findFinalSymbols(expresion []postfix.Symbol) []string {
 //
 // - lastPos([]int): Set of nodes ID's that comprehend its lastpos
 func getNodePosition(root *node, positionTable map[int]positionTableRow) (bool, []int, []int) {
+	// If node is an operation built apart
+	if root.Embedded != nil {
+		for id, row := range root.Embedded.rows {
+			positionTable[id] = row
+		}
+		positionTable[root.Id] = positionTableRow{
+			token:    root.Value,
+			nullable: root.Embedded.nullable,
+			firstPos: root.Embedded.firstPos,
+			lastPos:  root.Embedded.lastPos,
+			action:   Action{Priority: postfix.NULL_ACTION_PRIORITY},
+		}
+		return root.Embedded.nullable, root.Embedded.firstPos, root.Embedded.lastPos
+	}
+
 	// If Node is an operator with 2 operands
 	if root.IsOperator && root.Operands == 2 {
 		if root.Value == "·" {
@@ -262,7 +258,7 @@ func setFollowPos(root *node, positionTable map[int]positionTableRow) {
 // Computes a list transitorial "nodes" based on the lastpos, first post and follow post
 // of positionTable.
 func simplifyStates(
-	tokens []string,
+	symbols *alphabet,
 	initState []int,
 	positionTable map[int]positionTableRow) []*nodeSet {
 
@@ -275,13 +271,13 @@ func simplifyStates(
 		queue = queue[1:]        // Pop the element
 
 		// Get SET for each character
-		for _, token := range tokens {
+		for _, token := range symbols.symbols {
 			// Being in the node A (currentState), computing the nextNode with transition "t"
 			//  ┌───┐    ┌───┐
 			//  │ A ┼─t─►│ B │
 			//  └───┘    └───┘
 			// The actions found for "t" will be returned as well, so node A can store them.
-			newSet, newActions := getNewNodeSetForToken(currentState.value, token, positionTable)
+			newSet, newActions := getNewNodeSetForToken(currentState.value, token, symbols, positionTable)
 
 			setAlreadyExist, repeatedSet := setExists(&newSet, states)
 
@@ -307,14 +303,14 @@ func simplifyStates(
 //
 // Also it returns the actions found for the token found. This actions will then be
 // transferred to the origin node.
-func getNewNodeSetForToken(items []int, token string, positionTable map[int]positionTableRow) (nodeSet, []Action) {
+func getNewNodeSetForToken(items []int, token string, symbols *alphabet, positionTable map[int]positionTableRow) (nodeSet, []Action) {
 	setItems := make([]int, 0, len(items))
 	actions := make([]Action, 0)
 
 	// Selecting rows from position table with desired ID's
 	for _, i := range items {
 		row := positionTable[i]
-		if row.token != token {
+		if !symbols.matches(row.token, token) {
 			continue
 		}
 		setItems = append(setItems, row.followPos...)
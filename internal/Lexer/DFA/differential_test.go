@@ -0,0 +1,303 @@
+package dfa
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// Differential testing: random expresions written in the subset of the syntax
+// shared with Go's regexp package are built through NewDFA, and both engines must
+// agree on which strings they match entirely. A divergence is minimized before
+// being reported, removing parts of the expresion and runes of the string while
+// the engines still disagree.
+
+// Runes used by characters and classes, and by the strings matched against them.
+const differentialRunes = "abkK1_-+.("
+const differentialInputRunes = differentialRunes + "zZ9 \nKé"
+
+// A random expresion. Characters and classes are leafs, with their text already
+// escaped, the rest are operators over their children.
+type regex struct {
+	op       string // "char", "class", "concat", "|", "*", "+" or "?"
+	value    string
+	children []*regex
+}
+
+func (r *regex) String() string {
+	switch r.op {
+	case "char", "class":
+		return r.value
+	case "concat":
+		var sb strings.Builder
+		for _, child := range r.children {
+			if child.op == "|" {
+				sb.WriteString("(" + child.String() + ")")
+			} else {
+				sb.WriteString(child.String())
+			}
+		}
+		return sb.String()
+	case "|":
+		parts := make([]string, len(r.children))
+		for i, child := range r.children {
+			parts[i] = child.String()
+		}
+		return strings.Join(parts, "|")
+	default: // A repetition, Go rejects nested ones like a**
+		child := r.children[0]
+		if child.op == "char" || child.op == "class" {
+			return child.String() + r.op
+		}
+		return "(" + child.String() + ")" + r.op
+	}
+}
+
+func randomRegex(random *rand.Rand, depth int) *regex {
+	if depth == 0 || random.Intn(4) == 0 {
+		if random.Intn(3) == 0 {
+			return &regex{op: "class", value: randomClass(random)}
+		}
+		return &regex{op: "char", value: escapeRune(rune(differentialRunes[random.Intn(len(differentialRunes))]))}
+	}
+
+	switch op := []string{"concat", "concat", "|", "*", "+", "?"}[random.Intn(6)]; op {
+	case "concat", "|":
+		children := make([]*regex, 2+random.Intn(2))
+		for i := range children {
+			children[i] = randomRegex(random, depth-1)
+		}
+		return &regex{op: op, children: children}
+	default:
+		return &regex{op: op, children: []*regex{randomRegex(random, depth-1)}}
+	}
+}
+
+func randomClass(random *rand.Rand) string {
+	if random.Intn(3) == 0 {
+		return []string{`\d`, `\w`, `\s`, `\D`, `\W`, `\S`}[random.Intn(6)]
+	}
+	var sb strings.Builder
+	sb.WriteString("[")
+	if random.Intn(3) == 0 {
+		sb.WriteString("^")
+	}
+	for i := 0; i < 1+random.Intn(2); i++ {
+		ranges := []string{"a", "b", "k", "K", "1", "_", "a-c", "j-z", "A-Z", "0-9"}
+		sb.WriteString(ranges[random.Intn(len(ranges))])
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Escapes every rune that is not a letter or a digit, both engines read them
+// as the rune itself.
+func escapeRune(r rune) string {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return string(r)
+	}
+	return `\` + string(r)
+}
+
+// Returns a string the expresion likely matches, so not only rejections are
+// compared. Classes and caseless characters take any rune that matches them.
+func (r *regex) randomMatch(random *rand.Rand, fold bool) string {
+	switch r.op {
+	case "char", "class":
+		pattern := r.value
+		if fold {
+			pattern = "(?i)" + pattern
+		}
+		matcher := regexp.MustCompile("^(?:" + pattern + ")$")
+		candidates := make([]rune, 0)
+		for _, c := range differentialInputRunes {
+			if matcher.MatchString(string(c)) {
+				candidates = append(candidates, c)
+			}
+		}
+		if len(candidates) == 0 {
+			return ""
+		}
+		return string(candidates[random.Intn(len(candidates))])
+	case "concat":
+		var sb strings.Builder
+		for _, child := range r.children {
+			sb.WriteString(child.randomMatch(random, fold))
+		}
+		return sb.String()
+	case "|":
+		return r.children[random.Intn(len(r.children))].randomMatch(random, fold)
+	default:
+		min, max := 0, 3
+		if r.op == "+" {
+			min = 1
+		} else if r.op == "?" {
+			max = 1
+		}
+		var sb strings.Builder
+		for i := min + random.Intn(max-min+1); i > 0; i-- {
+			sb.WriteString(r.children[0].randomMatch(random, fold))
+		}
+		return sb.String()
+	}
+}
+
+func randomInput(random *rand.Rand) string {
+	input := []rune(differentialInputRunes)
+	var sb strings.Builder
+	for i := random.Intn(6); i > 0; i-- {
+		sb.WriteRune(input[random.Intn(len(input))])
+	}
+	return sb.String()
+}
+
+// Builds the DFA of a single rule, the same way buildRules does. Failures and
+// panics are returned as errors.
+func buildPattern(pattern string, fold bool) (automata *DFA, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	raw := []postfix.RawSymbol{{Value: "(", Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}}}
+	for _, r := range pattern {
+		raw = append(raw, postfix.RawSymbol{Value: string(r), Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}, Fold: fold})
+	}
+	raw = append(raw,
+		postfix.RawSymbol{Value: ")", Action: postfix.Action{Priority: postfix.NULL_ACTION_PRIORITY}},
+		postfix.RawSymbol{Value: "10", Action: postfix.Action{Priority: 0, Code: "{ return 0 }"}},
+	)
+	automata, _, err = NewDFA(raw, false, false)
+	return automata, err
+}
+
+// Describes how both engines disagree on a string, empty if they don't.
+func diverges(r *regex, fold bool, input string) string {
+	pattern := r.String()
+	goPattern := "^(?:" + pattern + ")$"
+	if fold {
+		goPattern = "(?i)" + goPattern
+	}
+	expected := regexp.MustCompile(goPattern).MatchString(input)
+
+	automata, err := buildPattern(pattern, fold)
+	if err != nil {
+		return fmt.Sprintf("%s: NewDFA fails with %q", pattern, err)
+	}
+	if _, got := automata.Match(input); got != expected {
+		return fmt.Sprintf("%s on %q: the DFA matches %t, regexp %t", pattern, input, got, expected)
+	}
+	return ""
+}
+
+// Expresions with one node less than r: a node is replaced by one of its
+// children, or a child of a concatenation or alternation is removed.
+func (r *regex) shrinks() []*regex {
+	shrinks := make([]*regex, 0)
+	shrinks = append(shrinks, r.children...)
+	if len(r.children) > 2 {
+		for i := range r.children {
+			children := append(append([]*regex{}, r.children[:i]...), r.children[i+1:]...)
+			shrinks = append(shrinks, &regex{op: r.op, children: children})
+		}
+	}
+	for i, child := range r.children {
+		for _, shrink := range child.shrinks() {
+			children := append([]*regex{}, r.children...)
+			children[i] = shrink
+			shrinks = append(shrinks, &regex{op: r.op, children: children})
+		}
+	}
+	return shrinks
+}
+
+// Shrinks an expresion and a string on which both engines disagree until no
+// smaller pair disagrees.
+func minimize(r *regex, input string, disagree func(*regex, string) bool) (*regex, string) {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range r.shrinks() {
+			if disagree(candidate, input) {
+				r, shrunk = candidate, true
+				break
+			}
+		}
+		runes := []rune(input)
+		for i := range runes {
+			candidate := string(runes[:i]) + string(runes[i+1:])
+			if disagree(r, candidate) {
+				input, shrunk = candidate, true
+				break
+			}
+		}
+	}
+	return r, input
+}
+
+func Test_differential(t *testing.T) {
+	// Divergences found before, (a*)? used to reject "aa"
+	regressions := []struct {
+		r     *regex
+		input string
+	}{
+		{&regex{op: "?", children: []*regex{{op: "*", children: []*regex{{op: "char", value: "a"}}}}}, "aa"},
+	}
+	for _, c := range regressions {
+		if problem := diverges(c.r, false, c.input); problem != "" {
+			t.Error(problem)
+		}
+	}
+
+	random := rand.New(rand.NewSource(45))
+	failures := 0
+	for i := 0; i < 400 && failures < 5; i++ {
+		r := randomRegex(random, 4)
+		fold := random.Intn(5) == 0
+		disagree := func(r *regex, input string) bool { return diverges(r, fold, input) != "" }
+
+		inputs := make([]string, 0, 30)
+		for j := 0; j < 15; j++ {
+			inputs = append(inputs, r.randomMatch(random, fold), randomInput(random))
+		}
+		for _, input := range inputs {
+			if !disagree(r, input) {
+				continue
+			}
+			small, smallInput := minimize(r, input, disagree)
+			flags := ""
+			if fold {
+				flags = "(?i)"
+			}
+			t.Errorf("%s%s\n\tfound on %s%s with %q", flags, diverges(small, fold, smallInput), flags, r, input)
+			failures++
+			break
+		}
+	}
+}
+
+func Test_minimize(t *testing.T) {
+	r := &regex{op: "concat", children: []*regex{
+		{op: "char", value: "a"},
+		{op: "*", children: []*regex{{op: "|", children: []*regex{
+			{op: "char", value: "b"}, {op: "char", value: "c"}, {op: "char", value: `\+`},
+		}}}},
+	}}
+	if r.String() != `a(b|c|\+)*` {
+		t.Fatalf("unexpected pattern %s", r)
+	}
+
+	// Pretends the engines disagree whenever there is a "+" on both
+	disagree := func(r *regex, input string) bool {
+		return strings.Contains(r.String(), "+") && strings.Contains(input, "+")
+	}
+	small, input := minimize(r, "ab++c", disagree)
+	if small.String() != `\+` || input != "+" {
+		t.Errorf("expected \\+ on \"+\", got %s on %q", small, input)
+	}
+}
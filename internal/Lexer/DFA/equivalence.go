@@ -0,0 +1,138 @@
+package dfa
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A piece of input recognized while tokenizing, Rule is -1 when no rule matches
+// the rune it starts with.
+type Lexeme struct {
+	Text string
+	Rule int
+}
+
+// Equivalent checks if two DFAs accept the same lexemes with the same actions. Two
+// actions are the same when their code is, regardless of its spaces, so rules
+// may be rewritten or reordered as long as each lexeme still runs the same code.
+//
+// Since tokenizing only depends on which action wins for each lexeme, equivalent
+// DFAs tokenize every input the same way. Otherwise the shortest lexeme where they
+// disagree is returned, which is also an input they tokenize differently.
+func Equivalent(a, b *DFA) (string, bool) {
+	// Runes both DFAs treat the same way, one of each interval is enough
+	samples := commonSamples(a, b)
+
+	type pair struct{ a, b *State } // nil stands for the dead state
+	type visit struct {
+		from pair
+		rune rune
+	}
+	start := pair{a.StartState, b.StartState}
+	visited := map[pair]visit{start: {}}
+	queue := []pair{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if winningCode(current.a) != winningCode(current.b) {
+			runes := make([]rune, 0)
+			for p := current; p != start; p = visited[p].from {
+				runes = append(runes, visited[p].rune)
+			}
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return string(runes), false
+		}
+
+		for _, r := range samples {
+			var next pair
+			if current.a != nil {
+				next.a = a.step(current.a, r)
+			}
+			if current.b != nil {
+				next.b = b.step(current.b, r)
+			}
+			if next.a == nil && next.b == nil {
+				continue
+			}
+			if _, seen := visited[next]; !seen {
+				visited[next] = visit{from: current, rune: r}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return "", true
+}
+
+// Tokenizes an input the way the generated lexer does: taking the longest lexeme
+// accepted each time, and a single rune when no rule matches.
+func (automata *DFA) Tokenize(input string) []Lexeme {
+	lexemes := make([]Lexeme, 0)
+	for start := 0; start < len(input); {
+		end, rule := start, -1
+		state := automata.StartState
+		for pos, r := range input[start:] {
+			state = automata.step(state, r)
+			if state == nil {
+				break
+			}
+			if len(state.Actions) > 0 {
+				end, rule = start+pos+utf8.RuneLen(r), state.Actions[0].Priority
+			}
+		}
+		if rule == -1 {
+			_, size := utf8.DecodeRuneInString(input[start:])
+			end = start + size
+		}
+		lexemes = append(lexemes, Lexeme{Text: input[start:end], Rule: rule})
+		start = end
+	}
+	return lexemes
+}
+
+// Code of the action that wins on a state, without spaces. Empty if none does.
+func winningCode(state *State) string {
+	if state == nil || len(state.Actions) == 0 {
+		return ""
+	}
+	return strings.Join(strings.Fields(state.Actions[0].Code), "")
+}
+
+// Splits the runes both DFAs can transition with into intervals where no symbol
+// of either of them starts or ends, and picks a rune of each one.
+func commonSamples(automatas ...*DFA) []rune {
+	boundaries := make([]rune, 0)
+	for _, automata := range automatas {
+		for _, state := range automata.States {
+			for symbol := range state.Transitions {
+				if automata.IsMarker(symbol) {
+					continue
+				}
+				for _, r := range automata.symbolRanges(symbol) {
+					boundaries = append(boundaries, r.Lo, r.Hi+1)
+				}
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+	boundaries = uniqueRunes(boundaries)
+
+	samples := make([]rune, 0, len(boundaries))
+	for k := 0; k+1 < len(boundaries); k++ {
+		lo, hi := boundaries[k], boundaries[k+1]-1
+		sample := lo
+		for c := lo; c <= hi && c < lo+128; c++ {
+			if unicode.IsGraphic(c) && !unicode.IsSpace(c) {
+				sample = c
+				break
+			}
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
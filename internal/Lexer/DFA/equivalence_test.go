@@ -0,0 +1,32 @@
+package dfa
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_equivalent(t *testing.T) {
+	// Same lexemes and actions, written in another way
+	a := buildRules(t, "[a-c]+", "[0-9]+", "x")
+	b := buildRules(t, "(a|b|c)(a|b|c)*", "\\d\\d*", "x")
+	if lexeme, equivalent := Equivalent(a, b); !equivalent {
+		t.Errorf("expected equivalent DFAs, they differ on %q", lexeme)
+	}
+
+	a = buildRules(t, "if", "[a-z]+")
+	b = buildRules(t, "if", "[a-z]+[0-9]*")
+	lexeme, equivalent := Equivalent(a, b)
+	if equivalent || lexeme != "a0" {
+		t.Fatalf("expected the DFAs to differ on \"a0\", got %q", lexeme)
+	}
+	if fmt.Sprint(a.Tokenize(lexeme)) != "[{a 1} {0 -1}]" || fmt.Sprint(b.Tokenize(lexeme)) != "[{a0 1}]" {
+		t.Errorf("unexpected tokens %v and %v", a.Tokenize(lexeme), b.Tokenize(lexeme))
+	}
+
+	// The rule that wins changes
+	a = buildRules(t, "if", "[a-z]+")
+	b = buildRules(t, "[a-z]+", "if")
+	if lexeme, _ := Equivalent(a, b); lexeme != "a" {
+		t.Errorf("expected the DFAs to differ on \"a\", got %q", lexeme)
+	}
+}
@@ -0,0 +1,234 @@
+package dfa
+
+import postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+
+// The direct method can't build intersections (r & s), differences (r - s) nor
+// complements (~r). Each one of them is built apart:
+//
+//  1. The automaton of each operand is built with the direct method, over the
+//     same alphabet as the whole expresion.
+//  2. Both are combined with the product construction, walking them at the
+//     same time. The complement swaps the accepting states instead.
+//  3. The result replaces the operation on the tree as a leaf with positions
+//     of its own, one for each transition. Reading a symbol moves to the
+//     positions of the transitions that leave the state reached, so the main
+//     automaton walks it the same way it walks any other sub-expresion.
+
+// Name of the set of every rune, added to the alphabet when there is a complement
+// so the complement can match runes no other symbol does.
+const UNIVERSE_SET = "(?~)"
+
+// A complete DFA built apart from the main one, the state 0 is the start.
+type automaton struct {
+	next      []map[Symbol]int // Missing symbols go to a dead state
+	accepting []bool
+}
+
+// Positions an operation adds to the position table of the main automaton.
+type embedding struct {
+	nullable bool
+	firstPos []int
+	lastPos  []int
+	rows     map[int]positionTableRow
+}
+
+// Replaces the operations of a tree with the automata built for them, innermost
+// first. New positions are numbered from nextId on.
+func buildOperations(n *node, symbols *alphabet, nextId *int) {
+	for i := range n.Children {
+		buildOperations(&n.Children[i], symbols, nextId)
+	}
+	if !n.IsOperator {
+		return
+	}
+
+	var result *automaton
+	switch n.Value {
+	case postfix.COMPLEMENT_SYMBOL:
+		result = complement(subAutomaton(n.Children[0], symbols, nextId), symbols)
+	case postfix.INTERSECTION_SYMBOL, postfix.DIFFERENCE_SYMBOL:
+		left := subAutomaton(n.Children[0], symbols, nextId)
+		right := subAutomaton(n.Children[1], symbols, nextId)
+		result = product(left, right, n.Value, symbols)
+	default:
+		return
+	}
+
+	*n = node{Id: n.Id, Value: n.Value, Embedded: embed(trim(result), symbols, nextId)}
+}
+
+// Builds the automaton of a sub-expresion with the direct method.
+func subAutomaton(n node, symbols *alphabet, nextId *int) *automaton {
+	centinel := node{Id: *nextId, Value: "#", IsFinal: true, Action: Action{Priority: postfix.NULL_ACTION_PRIORITY}}
+	root := node{Id: -(*nextId + 1), Value: "·", Operands: 2, Children: []node{n, centinel}, IsOperator: true}
+	*nextId += 2
+
+	positionTable := make(map[int]positionTableRow)
+	_, firstPos, _ := getNodePosition(&root, positionTable)
+	setFollowPos(&root, positionTable)
+	sets := simplifyStates(symbols, firstPos, positionTable)
+
+	result := &automaton{next: make([]map[Symbol]int, len(sets)), accepting: make([]bool, len(sets))}
+	for _, set := range sets {
+		result.next[set.id] = make(map[Symbol]int)
+		for symbol, next := range set.transitions {
+			if !symbols.isMarker(symbol) && len(next.value) > 0 {
+				result.next[set.id][symbol] = next.id
+			}
+		}
+		for _, item := range set.value {
+			if item == centinel.Id {
+				result.accepting[set.id] = true
+			}
+		}
+	}
+	return result
+}
+
+// Walks both automata at the same time. A pair accepts when both of them accept
+// (&) or when only the left one does (-).
+func product(left, right *automaton, operation string, symbols *alphabet) *automaton {
+	const DEAD = -1
+	index := map[[2]int]int{{0, 0}: 0}
+	pairs := [][2]int{{0, 0}}
+	result := &automaton{}
+
+	for i := 0; i < len(pairs); i++ {
+		l, r := pairs[i][0], pairs[i][1]
+		result.next = append(result.next, make(map[Symbol]int))
+		accepting := left.accepting[l] && (r == DEAD || !right.accepting[r])
+		if operation == postfix.INTERSECTION_SYMBOL {
+			accepting = left.accepting[l] && r != DEAD && right.accepting[r]
+		}
+		result.accepting = append(result.accepting, accepting)
+
+		for _, symbol := range symbols.symbols {
+			nextLeft, exist := left.next[l][symbol]
+			if !exist {
+				continue // Neither operation accepts without the left side
+			}
+			nextRight := DEAD
+			if r != DEAD {
+				if next, exist := right.next[r][symbol]; exist {
+					nextRight = next
+				}
+			}
+			if nextRight == DEAD && operation == postfix.INTERSECTION_SYMBOL {
+				continue
+			}
+
+			pair := [2]int{nextLeft, nextRight}
+			if _, exist := index[pair]; !exist {
+				index[pair] = len(pairs)
+				pairs = append(pairs, pair)
+			}
+			result.next[i][symbol] = index[pair]
+		}
+	}
+	return result
+}
+
+// Accepts every text the automaton doesn't. Missing transitions go to a new
+// state that accepts and stays there with any symbol.
+func complement(a *automaton, symbols *alphabet) *automaton {
+	sink := len(a.next)
+	result := &automaton{next: make([]map[Symbol]int, sink+1), accepting: make([]bool, sink+1)}
+	for state := 0; state <= sink; state++ {
+		result.next[state] = make(map[Symbol]int)
+		result.accepting[state] = state == sink || !a.accepting[state]
+		for _, symbol := range symbols.symbols {
+			if symbols.isMarker(symbol) {
+				continue
+			}
+			result.next[state][symbol] = sink
+			if state < sink {
+				if next, exist := a.next[state][symbol]; exist {
+					result.next[state][symbol] = next
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Removes the transitions to states that can never accept. The start state is
+// always kept.
+func trim(a *automaton) *automaton {
+	alive := make([]bool, len(a.next))
+	copy(alive, a.accepting)
+	for changed := true; changed; {
+		changed = false
+		for state, transitions := range a.next {
+			if alive[state] {
+				continue
+			}
+			for _, next := range transitions {
+				if alive[next] {
+					alive[state], changed = true, true
+					break
+				}
+			}
+		}
+	}
+
+	for _, transitions := range a.next {
+		for symbol, next := range transitions {
+			if !alive[next] {
+				delete(transitions, symbol)
+			}
+		}
+	}
+	return a
+}
+
+// Turns an automaton into positions: one for each pair of symbol and state it
+// goes to. The positions that follow one are the transitions that leave its state.
+func embed(a *automaton, symbols *alphabet, nextId *int) *embedding {
+	type transition struct {
+		symbol Symbol
+		state  int
+	}
+	positions := make(map[transition]int)
+	order := make([]transition, 0)
+	position := func(symbol Symbol, state int) int {
+		key := transition{symbol, state}
+		if id, exist := positions[key]; exist {
+			return id
+		}
+		positions[key] = *nextId
+		order = append(order, key)
+		*nextId++
+		return positions[key]
+	}
+
+	// Positions of the transitions that leave each state
+	leaving := make([][]int, len(a.next))
+	for state, transitions := range a.next {
+		for _, symbol := range symbols.symbols {
+			if next, exist := transitions[symbol]; exist {
+				leaving[state] = append(leaving[state], position(symbol, next))
+			}
+		}
+	}
+
+	result := &embedding{
+		nullable: a.accepting[0],
+		firstPos: leaving[0],
+		lastPos:  make([]int, 0),
+		rows:     make(map[int]positionTableRow, len(positions)),
+	}
+	for _, key := range order {
+		id := positions[key]
+		result.rows[id] = positionTableRow{
+			token:     key.symbol,
+			firstPos:  []int{id},
+			lastPos:   []int{id},
+			followPos: leaving[key.state],
+			action:    Action{Priority: postfix.NULL_ACTION_PRIORITY},
+		}
+		if a.accepting[key.state] {
+			result.lastPos = append(result.lastPos, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,183 @@
+package dfa
+
+import (
+	"math/rand"
+	"sort"
+	"unicode"
+)
+
+type SampleOptions struct {
+	Count     int // Maximum number of samples
+	MaxLength int // Maximum runes of a sample
+	// When set, samples are random walks over the DFA. Otherwise they are
+	// enumerated from the shortest to the longest.
+	Random *rand.Rand
+}
+
+// Samples returns lexemes that produce the token of a rule: when given to the lexer
+// as the whole input, the rule wins over every other one. Rules that never win
+// have no samples.
+//
+// Each transition symbol stands for a set of runes. Enumerating takes a single
+// rune of each set, visible ones preferred, random samples take any rune of it.
+func (automata *DFA) Samples(rule int, options SampleOptions) []string {
+	distance := automata.distanceToRule(rule)
+	samples := make([]string, 0, options.Count)
+	if d, reachable := distance[automata.StartState]; !reachable || d > options.MaxLength || options.Count <= 0 {
+		return samples
+	}
+
+	if options.Random != nil {
+		seen := make(map[string]bool)
+		for attempt := 0; attempt < options.Count*20 && len(samples) < options.Count; attempt++ {
+			sample := automata.randomSample(rule, distance, options.MaxLength, options.Random)
+			if !seen[sample] {
+				seen[sample] = true
+				samples = append(samples, sample)
+			}
+		}
+		return samples
+	}
+
+	// Shorter lexemes first, and depth first within a length. A move is only
+	// taken when the rule can still win with exactly the runes left, so every
+	// branch ends in a sample and the search never outgrows them. Since the DFA
+	// is deterministic every path is a different lexeme.
+	finish := automata.finishingStates(rule, options.MaxLength)
+	var enumerate func(state *State, left int, runes []rune)
+	enumerate = func(state *State, left int, runes []rune) {
+		if len(samples) == options.Count {
+			return
+		}
+		if left == 0 {
+			samples = append(samples, string(runes))
+			return
+		}
+		for _, symbol := range automata.sortedSymbols(state) {
+			next := state.Transitions[symbol]
+			if finish[left-1][next] {
+				enumerate(next, left-1, append(append([]rune{}, runes...), []rune(automata.sampleRune(symbol))...))
+			}
+		}
+	}
+	for length := distance[automata.StartState]; length <= options.MaxLength; length++ {
+		enumerate(automata.StartState, length, nil)
+	}
+	return samples
+}
+
+// States where the rule wins after exactly some number of runes, for each
+// number up to the maximum: finish[n][state] when n runes lead from it to the rule.
+func (automata *DFA) finishingStates(rule int, maxLength int) []map[*State]bool {
+	finish := make([]map[*State]bool, maxLength+1)
+	finish[0] = make(map[*State]bool)
+	for _, state := range automata.States {
+		if wins(state, rule) {
+			finish[0][state] = true
+		}
+	}
+	for n := 1; n <= maxLength; n++ {
+		finish[n] = make(map[*State]bool)
+		for _, state := range automata.States {
+			for symbol, next := range state.Transitions {
+				if !automata.IsMarker(symbol) && finish[n-1][next] {
+					finish[n][state] = true
+					break
+				}
+			}
+		}
+	}
+	return finish
+}
+
+// Walks the DFA choosing at random between the transitions that can still reach
+// the rule on time, and stopping where the rule wins.
+func (automata *DFA) randomSample(rule int, distance map[*State]int, maxLength int, random *rand.Rand) string {
+	state := automata.StartState
+	runes := make([]rune, 0)
+	for {
+		choices := make([]Symbol, 0)
+		for _, symbol := range automata.sortedSymbols(state) {
+			if d, useful := distance[state.Transitions[symbol]]; useful && len(runes)+1+d <= maxLength {
+				choices = append(choices, symbol)
+			}
+		}
+
+		// Stopping is one more choice where the rule wins. The distances make
+		// sure there is always something to choose.
+		options := len(choices)
+		if wins(state, rule) {
+			options++
+		}
+		option := random.Intn(options)
+		if option == len(choices) {
+			return string(runes)
+		}
+
+		symbol := choices[option]
+		runes = append(runes, automata.randomRune(symbol, random))
+		state = state.Transitions[symbol]
+	}
+}
+
+// Picks a rune of a transition symbol, trying to get a visible one.
+func (automata *DFA) randomRune(symbol Symbol, random *rand.Rand) rune {
+	ranges := automata.symbolRanges(symbol)
+	var r rune
+	for attempt := 0; attempt < 10; attempt++ {
+		interval := ranges[random.Intn(len(ranges))]
+		r = interval.Lo + rune(random.Int63n(int64(interval.Hi-interval.Lo)+1))
+		if unicode.IsGraphic(r) {
+			break
+		}
+	}
+	return r
+}
+
+// Runes needed from each state to reach one where the rule wins, states that
+// can't reach it are left out.
+func (automata *DFA) distanceToRule(rule int) map[*State]int {
+	incoming := make(map[*State][]*State)
+	distance := make(map[*State]int)
+	queue := make([]*State, 0)
+	for _, state := range automata.States {
+		for symbol, next := range state.Transitions {
+			if !automata.IsMarker(symbol) {
+				incoming[next] = append(incoming[next], state)
+			}
+		}
+		if wins(state, rule) {
+			distance[state] = 0
+			queue = append(queue, state)
+		}
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, previous := range incoming[state] {
+			if _, visited := distance[previous]; !visited {
+				distance[previous] = distance[state] + 1
+				queue = append(queue, previous)
+			}
+		}
+	}
+	return distance
+}
+
+// Transition symbols of a state that stand for runes, sorted.
+func (automata *DFA) sortedSymbols(state *State) []Symbol {
+	symbols := make([]Symbol, 0, len(state.Transitions))
+	for symbol := range state.Transitions {
+		if !automata.IsMarker(symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Checks if a rule is the one that wins on a state.
+func wins(state *State, rule int) bool {
+	return len(state.Actions) > 0 && state.Actions[0].Priority == rule
+}
@@ -0,0 +1,79 @@
+package dfa
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func Test_samples(t *testing.T) {
+	automata := buildRules(t, "if", "[a-zñ]+", "[0-9]+\\.[0-9]+")
+
+	check := func(rule int, samples []string, options SampleOptions) {
+		seen := make(map[string]bool)
+		for _, sample := range samples {
+			if seen[sample] || utf8.RuneCountInString(sample) > options.MaxLength {
+				t.Errorf("rule %d: unexpected sample %q", rule, sample)
+			}
+			seen[sample] = true
+			if tokens := automata.Tokenize(sample); len(tokens) != 1 || tokens[0].Rule != rule {
+				t.Errorf("rule %d: %q is tokenized as %v", rule, sample, tokens)
+			}
+		}
+	}
+
+	options := SampleOptions{Count: 10, MaxLength: 4}
+	for rule := 0; rule < 3; rule++ {
+		samples := automata.Samples(rule, options)
+		t.Log(rule, samples)
+		check(rule, samples, options)
+		options.Random = rand.New(rand.NewSource(int64(rule)))
+		samples = automata.Samples(rule, options)
+		t.Log(rule, samples)
+		check(rule, samples, options)
+		options.Random = nil
+	}
+
+	if samples := automata.Samples(0, options); fmt.Sprint(samples) != "[if]" {
+		t.Errorf("expected only \"if\", got %v", samples)
+	}
+	if samples := automata.Samples(1, options); len(samples) != 10 || len(samples[0]) != 1 {
+		t.Errorf("expected 10 samples starting by the shortest, got %v", samples)
+	}
+	if samples := automata.Samples(2, SampleOptions{Count: 10, MaxLength: 2}); len(samples) != 0 {
+		t.Errorf("expected no samples shorter than 3 runes, got %v", samples)
+	}
+
+	// A rule that never wins
+	automata = buildRules(t, "[a-z]+", "if")
+	if samples := automata.Samples(1, options); len(samples) != 0 {
+		t.Errorf("expected no samples, got %v", samples)
+	}
+}
+
+// A long fixed suffix behind a star used to grow the search with every prefix
+// of the star, each sample must be found without going through the others.
+func Test_samplesSuffix(t *testing.T) {
+	automata := buildRules(t, "[a-z]*abcdefg")
+	done := make(chan []string)
+	go func() {
+		done <- automata.Samples(0, SampleOptions{Count: 3, MaxLength: 40})
+	}()
+
+	select {
+	case samples := <-done:
+		if len(samples) != 3 || samples[0] != "abcdefg" || len(samples[1]) != 8 || len(samples[2]) != 8 {
+			t.Errorf("expected abcdefg and then 2 samples of 8 runes, got %v", samples)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sampling a star followed by a long suffix doesn't finish")
+	}
+
+	// A rune for each class of [a-z] the suffix splits it in: 1 + 8 + 64
+	samples := automata.Samples(0, SampleOptions{Count: 100, MaxLength: 9})
+	if len(samples) != 73 || len(samples[len(samples)-1]) != 9 {
+		t.Errorf("expected 73 samples up to 9 runes, got %d", len(samples))
+	}
+}
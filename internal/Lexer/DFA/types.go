@@ -1,6 +1,10 @@
 package dfa
 
-import "fmt"
+import (
+	"fmt"
+
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
 
 type Symbol = string
 
@@ -11,6 +15,9 @@ type Symbol = string
 type DFA struct {
 	StartState *State
 	States     []*State
+	// Transition symbols that stand for several runes. Ex: "[a-z]": {{'a', 'z'}}
+	// Any other symbol matches the rune it is made of.
+	Classes map[Symbol][]postfix.RuneRange
 }
 
 type State struct {
@@ -70,6 +77,9 @@ type node struct {
 	IsFinal bool
 	// For special Symbols encapsulate logic to execute when a pattern is meet
 	Action Action
+	// Intersections, differences and complements already built, they are leaves
+	// with several positions
+	Embedded *embedding
 }
 
 func (n node) String() string {
@@ -0,0 +1,157 @@
+package Lex_writer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	pf "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// Writes the automata as a scan function where every state is a labeled block,
+// like re2c does. ASCII runes are matched with a switch and the rest with ranges:
+//
+//	state1:
+//		acceptEnd, acceptRule = pos, 3
+//		...
+//		switch r {
+//		case 'a', 'b':
+//			pos += size
+//			goto state1
+//		}
+//		if r >= utf8.RuneSelf && inRanges(r, state1To2) {
+//			pos += size
+//			goto state2
+//		}
+//		return acceptEnd, acceptRule, pos
+//
+// Unlike the tables, no arrays are involved while scanning.
+func writeDirect(adf *dfa.DFA) string {
+	states := sortedStates(adf)
+	index := make(map[*dfa.State]int, len(states))
+	for i, state := range states {
+		index[state] = i
+	}
+
+	// Runes each state can transition with, grouped by the state they go to
+	edges := make([]map[int][]pf.RuneRange, len(states))
+	targeted := make(map[int]bool)
+	for i, state := range states {
+		edges[i] = make(map[int][]pf.RuneRange)
+		for symbol, next := range state.Transitions {
+			if adf.IsMarker(symbol) {
+				continue
+			}
+			target := index[next]
+			edges[i][target] = append(edges[i][target], symbolRanges(adf, symbol)...)
+			targeted[target] = true
+		}
+	}
+
+	tables := buildTables(adf) // Only to know what each state accepts
+	startAccepts := tables.accept[0] >= 0 && targeted[0]
+
+	var body, ranges strings.Builder
+	body.WriteString("func scan(content string, pos int) (int, int32, int) {\n")
+	body.WriteString("acceptEnd, acceptRule := -1, int32(-1)\n")
+	if len(targeted) > 0 {
+		body.WriteString("var r rune\nvar size int\n")
+	}
+	if startAccepts {
+		// The start state accepts only when entered again, not at the beginning
+		body.WriteString("goto state0\n")
+	}
+
+	for i := range states {
+		fmt.Fprintf(&body, "\n// State %s\n", states[i].Id)
+		if i == 0 && startAccepts {
+			fmt.Fprintf(&body, "state0Accept:\nacceptEnd, acceptRule = pos, %d\n", tables.accept[0])
+		}
+		if targeted[i] {
+			fmt.Fprintf(&body, "state%d:\n", i)
+		}
+		if i != 0 && tables.accept[i] >= 0 {
+			fmt.Fprintf(&body, "acceptEnd, acceptRule = pos, %d\n", tables.accept[i])
+		}
+
+		targets := make([]int, 0, len(edges[i]))
+		for target := range edges[i] {
+			targets = append(targets, target)
+		}
+		sort.Ints(targets)
+
+		if len(targets) > 0 {
+			body.WriteString("if pos >= len(content) {\nreturn acceptEnd, acceptRule, pos\n}\n")
+			body.WriteString("r, size = rune(content[pos]), 1\n")
+			body.WriteString("if r >= utf8.RuneSelf {\nr, size = utf8.DecodeRuneInString(content[pos:])\n}\n")
+
+			label := func(target int) string {
+				if target == 0 && startAccepts {
+					return "state0Accept"
+				}
+				return "state" + strconv.Itoa(target)
+			}
+
+			// ASCII runes
+			var cases strings.Builder
+			for _, target := range targets {
+				runes := make([]string, 0)
+				for _, r := range mergeRanges(edges[i][target]) {
+					for c := r.Lo; c <= r.Hi && c < utf8.RuneSelf; c++ {
+						runes = append(runes, strconv.QuoteRune(c))
+					}
+				}
+				if len(runes) > 0 {
+					fmt.Fprintf(&cases, "case %s:\npos += size\ngoto %s\n", strings.Join(runes, ", "), label(target))
+				}
+			}
+			if cases.Len() > 0 {
+				fmt.Fprintf(&body, "switch r {\n%s}\n", cases.String())
+			}
+
+			// Runes above ASCII
+			for _, target := range targets {
+				wide := make([]pf.RuneRange, 0)
+				for _, r := range mergeRanges(edges[i][target]) {
+					if r.Hi >= utf8.RuneSelf {
+						wide = append(wide, pf.RuneRange{Lo: max(r.Lo, utf8.RuneSelf), Hi: r.Hi})
+					}
+				}
+				if len(wide) == 0 {
+					continue
+				}
+				name := fmt.Sprintf("state%dTo%d", i, target)
+				fmt.Fprintf(&ranges, "var %s = []runeRange{\n", name)
+				for _, r := range wide {
+					fmt.Fprintf(&ranges, "{lo: 0x%X, hi: 0x%X},\n", r.Lo, r.Hi)
+				}
+				ranges.WriteString("}\n\n")
+				fmt.Fprintf(&body, "if r >= utf8.RuneSelf && inRanges(r, %s) {\npos += size\ngoto %s\n}\n", name, label(target))
+			}
+		}
+		body.WriteString("return acceptEnd, acceptRule, pos\n")
+	}
+	body.WriteString("}\n")
+
+	return ranges.String() + body.String()
+}
+
+// Sorts a list of ranges merging the ones that overlap or are next to each other.
+func mergeRanges(ranges []pf.RuneRange) []pf.RuneRange {
+	sorted := append([]pf.RuneRange{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Lo < sorted[j].Lo })
+
+	merged := make([]pf.RuneRange, 0, len(sorted))
+	for _, r := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && r.Lo <= merged[last].Hi+1 {
+			merged[last].Hi = max(merged[last].Hi, r.Hi)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
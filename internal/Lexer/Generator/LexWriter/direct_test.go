@@ -0,0 +1,64 @@
+package Lex_writer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	pf "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// [a-c]+ | x, where the start state accepts when it is entered again with y
+func Test_writeDirect(t *testing.T) {
+	q0 := &dfa.State{Id: "0", Transitions: make(map[dfa.Symbol]*dfa.State),
+		Actions: []dfa.Action{{Code: "{ return Y }", Priority: 2}}}
+	q1 := &dfa.State{Id: "1", Transitions: make(map[dfa.Symbol]*dfa.State),
+		Actions: []dfa.Action{{Code: "{ return ID }", Priority: 0}}}
+	q2 := &dfa.State{Id: "2", Transitions: make(map[dfa.Symbol]*dfa.State),
+		Actions: []dfa.Action{{Code: "{ return X }", Priority: 1}}}
+
+	q0.Transitions["[a-b]"] = q1
+	q0.Transitions["c"] = q1
+	q0.Transitions["x"] = q2
+	q0.Transitions["ñ"] = q2
+	q0.Transitions["y"] = q0
+	q1.Transitions["[a-b]"] = q1
+	q1.Transitions["10"] = q2 // Markers are not runes
+
+	automata := &dfa.DFA{
+		StartState: q0,
+		States:     []*dfa.State{q2, q1, q0},
+		Classes:    map[dfa.Symbol][]pf.RuneRange{"[a-b]": {{Lo: 'a', Hi: 'b'}}},
+	}
+
+	code := writeDirect(automata)
+	fmt.Println(code)
+
+	expected := []string{
+		"goto state0\n",
+		"state0Accept:\nacceptEnd, acceptRule = pos, 2\nstate0:\n",
+		"case 'a', 'b', 'c':\npos += size\ngoto state1\n",
+		"case 'x':\npos += size\ngoto state2\n",
+		"case 'y':\npos += size\ngoto state0Accept\n",
+		"var state0To2 = []runeRange{\n{lo: 0xF1, hi: 0xF1},\n}",
+		"state1:\nacceptEnd, acceptRule = pos, 0\n",
+		"state2:\nacceptEnd, acceptRule = pos, 1\nreturn acceptEnd, acceptRule, pos\n",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(code, fragment) {
+			t.Errorf("expected the scanner to contain %q", fragment)
+		}
+	}
+	if strings.Contains(code, "10") {
+		t.Errorf("markers should not be written as transitions")
+	}
+}
+
+func Test_mergeRanges(t *testing.T) {
+	ranges := []pf.RuneRange{{Lo: 'x', Hi: 'z'}, {Lo: 'a', Hi: 'c'}, {Lo: 'd', Hi: 'd'}, {Lo: 'b', Hi: 'e'}}
+	merged := mergeRanges(ranges)
+	if fmt.Sprint(merged) != fmt.Sprint([]pf.RuneRange{{Lo: 'a', Hi: 'e'}, {Lo: 'x', Hi: 'z'}}) {
+		t.Errorf("unexpected merged ranges %v", merged)
+	}
+}
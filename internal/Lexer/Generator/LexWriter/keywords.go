@@ -0,0 +1,111 @@
+package Lex_writer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Literal rules like "while" are usually also matched by an identifier rule. Instead
+// of giving each of them its own path on the DFA, they are left out of it and
+// looked up once the identifier rule (the host) matches:
+//
+//	keywords43[keywordHash(lexeme, seed)&mask]
+//
+// The seed is searched so every keyword of a host lands on a different slot
+// (a perfect hash), a lookup is then a hash and a single comparison.
+
+// Maximum seeds tried on a table size before making the table bigger.
+const MAX_KEYWORD_SEEDS = 1 << 16
+
+// A keyword table for the lexemes of a host rule.
+type keywordTable struct {
+	host  int
+	seed  uint32
+	slots []Keyword // Keywords by their hash, empty slots have an empty text
+}
+
+// Must be the same function as keywordHash in the LexTemplate.go
+func keywordHash(s string, seed uint32) uint32 {
+	h := seed ^ 2166136261
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h ^ h>>16 // Lets the whole seed reach the low bits
+}
+
+// Builds a perfect hash table for the keywords of each host rule, sorted by host.
+func buildKeywordTables(keywords []Keyword) []keywordTable {
+	byHost := make(map[int][]Keyword)
+	for _, keyword := range keywords {
+		byHost[keyword.Host] = append(byHost[keyword.Host], keyword)
+	}
+	hosts := make([]int, 0, len(byHost))
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Ints(hosts)
+
+	tables := make([]keywordTable, 0, len(hosts))
+	for _, host := range hosts {
+		tables = append(tables, perfectHash(host, byHost[host]))
+	}
+	return tables
+}
+
+// Searches a seed that gives every keyword a different slot, on the smallest
+// power of two table where one is found.
+func perfectHash(host int, keywords []Keyword) keywordTable {
+	size := 1
+	for size < len(keywords) {
+		size <<= 1
+	}
+
+	for ; ; size <<= 1 {
+		mask := uint32(size - 1)
+	seeds:
+		for seed := uint32(0); seed < MAX_KEYWORD_SEEDS; seed++ {
+			slots := make([]Keyword, size)
+			for _, keyword := range keywords {
+				slot := keywordHash(keyword.Text, seed) & mask
+				if slots[slot].Text != "" {
+					continue seeds
+				}
+				slots[slot] = keyword
+			}
+			return keywordTable{host: host, seed: seed, slots: slots}
+		}
+	}
+}
+
+// Writes the keyword tables and the keywordRule function, which swaps the rule
+// matched by a host for the rule of the keyword its lexeme is.
+func writeKeywords(keywords []Keyword) string {
+	tables := buildKeywordTables(keywords)
+
+	var sb strings.Builder
+	for _, table := range tables {
+		fmt.Fprintf(&sb, "// Keywords matched by the rule %d\n", table.host)
+		fmt.Fprintf(&sb, "var keywords%d = [%d]keyword{\n", table.host, len(table.slots))
+		for _, slot := range table.slots {
+			if slot.Text == "" {
+				sb.WriteString("{rule: -1},\n")
+				continue
+			}
+			fmt.Fprintf(&sb, "{text: %q, rule: %d},\n", slot.Text, slot.Rule)
+		}
+		sb.WriteString("}\n\n")
+	}
+
+	sb.WriteString("// Returns the rule of the keyword a lexeme is, or the rule that matched it otherwise.\n")
+	sb.WriteString("func keywordRule(rule int32, lexeme string) int32 {\nswitch rule {\n")
+	for _, table := range tables {
+		fmt.Fprintf(&sb, "case %d:\n", table.host)
+		fmt.Fprintf(&sb, "if k := keywords%d[keywordHash(lexeme, %d)&%d]; k.rule >= 0 && k.text == lexeme {\nreturn k.rule\n}\n",
+			table.host, table.seed, len(table.slots)-1)
+	}
+	sb.WriteString("}\nreturn rule\n}\n")
+
+	return sb.String()
+}
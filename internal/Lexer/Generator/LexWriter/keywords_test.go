@@ -0,0 +1,46 @@
+package Lex_writer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_writeKeywords(t *testing.T) {
+	keywords := []Keyword{
+		{Text: "if", Rule: 0, Host: 5},
+		{Text: "else", Rule: 1, Host: 5},
+		{Text: "while", Rule: 2, Host: 5},
+		{Text: "func", Rule: 3, Host: 5},
+		{Text: "true", Rule: 4, Host: 6},
+	}
+
+	tables := buildKeywordTables(keywords)
+	if len(tables) != 2 || tables[0].host != 5 || tables[1].host != 6 {
+		t.Fatalf("expected a table for the hosts 5 and 6, got %v", tables)
+	}
+	for _, table := range tables {
+		found := 0
+		for slot, keyword := range table.slots {
+			if keyword.Text == "" {
+				continue
+			}
+			found++
+			if int(keywordHash(keyword.Text, table.seed))&(len(table.slots)-1) != slot {
+				t.Errorf("keyword %q is not on its slot", keyword.Text)
+			}
+		}
+		if table.host == 5 && (found != 4 || len(table.slots) > 8) {
+			t.Errorf("unexpected table for host 5 %v", table.slots)
+		}
+	}
+
+	code := writeKeywords(keywords)
+	fmt.Println(code)
+	if !strings.Contains(code, "case 5:\n") || !strings.Contains(code, `{text: "while", rule: 2}`) {
+		t.Errorf("unexpected keyword code %s", code)
+	}
+	if !strings.Contains(writeKeywords(nil), "switch rule {\n}\nreturn rule\n") {
+		t.Errorf("without keywords the rule should be returned as it is")
+	}
+}
@@ -0,0 +1,208 @@
+package Lex_writer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	pf "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// The generated lexer doesn't walk the DFA through maps, it uses flat tables:
+//
+//	every rune belongs to an equivalence class, runes of a class have the same
+//	transitions on every state. Class 0 holds the runes no state can transition with.
+//	transitions[state*numClasses + class] is the next state, -1 if there is none.
+//	acceptRules[state] is the rule accepted by the state, -1 if there is none.
+//
+// The start state is always the state 0.
+type lexTables struct {
+	numClasses  int
+	ascii       [utf8.RuneSelf]int // Class of each ASCII rune
+	ranges      []classRange       // Classes of the runes above ASCII, sorted and disjoint
+	transitions []int
+	accept      []int
+	actions     map[int]string // Code of each rule by its priority
+}
+
+type classRange struct {
+	pf.RuneRange
+	class int
+}
+
+func buildTables(adf *dfa.DFA) lexTables {
+	states := sortedStates(adf)
+	index := make(map[*dfa.State]int, len(states))
+	for i, state := range states {
+		index[state] = i
+	}
+
+	// Symbols with the same transitions on every state are merged in a single class
+	symbols := make([]dfa.Symbol, 0)
+	seen := make(map[dfa.Symbol]struct{})
+	for _, state := range states {
+		for symbol := range state.Transitions {
+			if _, exist := seen[symbol]; exist || adf.IsMarker(symbol) {
+				continue
+			}
+			seen[symbol] = struct{}{}
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		return symbolRanges(adf, symbols[i])[0].Lo < symbolRanges(adf, symbols[j])[0].Lo
+	})
+
+	tables := lexTables{numClasses: 1, actions: make(map[int]string)}
+	classes := make(map[string]int) // Class of each column of transitions
+	columns := [][]int{nil}         // Column of transitions of each class
+	runeRanges := make([]classRange, 0)
+	for _, symbol := range symbols {
+		column := make([]int, len(states))
+		for i, state := range states {
+			column[i] = -1
+			if next, exist := state.Transitions[symbol]; exist {
+				column[i] = index[next]
+			}
+		}
+
+		key := fmt.Sprint(column)
+		class, exist := classes[key]
+		if !exist {
+			class = tables.numClasses
+			classes[key] = class
+			columns = append(columns, column)
+			tables.numClasses++
+		}
+		for _, r := range symbolRanges(adf, symbol) {
+			runeRanges = append(runeRanges, classRange{r, class})
+		}
+	}
+
+	// ASCII runes are looked up directly, the rest through ranges
+	sort.Slice(runeRanges, func(i, j int) bool { return runeRanges[i].Lo < runeRanges[j].Lo })
+	for _, r := range runeRanges {
+		for c := r.Lo; c <= r.Hi && c < utf8.RuneSelf; c++ {
+			tables.ascii[c] = r.class
+		}
+		if r.Hi < utf8.RuneSelf {
+			continue
+		}
+		r.Lo = max(r.Lo, utf8.RuneSelf)
+		last := len(tables.ranges) - 1
+		if last >= 0 && tables.ranges[last].class == r.class && tables.ranges[last].Hi+1 == r.Lo {
+			tables.ranges[last].Hi = r.Hi
+			continue
+		}
+		tables.ranges = append(tables.ranges, r)
+	}
+
+	tables.transitions = make([]int, len(states)*tables.numClasses)
+	tables.accept = make([]int, len(states))
+	for i, state := range states {
+		for class := 0; class < tables.numClasses; class++ {
+			tables.transitions[i*tables.numClasses+class] = -1
+			if class > 0 {
+				tables.transitions[i*tables.numClasses+class] = columns[class][i]
+			}
+		}
+
+		// The action with higher priority (lower number) wins
+		tables.accept[i] = -1
+		for _, action := range state.Actions {
+			if tables.accept[i] == -1 || action.Priority < tables.accept[i] {
+				tables.accept[i] = action.Priority
+			}
+			tables.actions[action.Priority] = action.Code
+		}
+	}
+
+	return tables
+}
+
+// States with the start state first, the rest sorted by their Id.
+func sortedStates(adf *dfa.DFA) []*dfa.State {
+	states := []*dfa.State{adf.StartState}
+	for _, state := range adf.States {
+		if state != adf.StartState {
+			states = append(states, state)
+		}
+	}
+	sort.SliceStable(states[1:], func(i, j int) bool {
+		a, _ := strconv.Atoi(states[i+1].Id)
+		b, _ := strconv.Atoi(states[j+1].Id)
+		return a < b
+	})
+	return states
+}
+
+// Runes matched by a transition symbol, either a class or a single rune.
+func symbolRanges(adf *dfa.DFA, symbol dfa.Symbol) []pf.RuneRange {
+	if ranges, isClass := adf.Classes[symbol]; isClass {
+		return ranges
+	}
+	r, _ := utf8.DecodeRuneInString(symbol)
+	return []pf.RuneRange{{Lo: r, Hi: r}}
+}
+
+// Writes the tables as Go declarations.
+func (t lexTables) write() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "const numClasses = %d\n\n", t.numClasses)
+
+	sb.WriteString("// Class of each ASCII rune\nvar asciiClasses = [utf8.RuneSelf]uint16{")
+	writeInts(&sb, t.ascii[:])
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// Classes of the runes above ASCII, sorted by lo\nvar classRanges = []classRange{\n")
+	for _, r := range t.ranges {
+		fmt.Fprintf(&sb, "{lo: 0x%X, hi: 0x%X, class: %d},\n", r.Lo, r.Hi, r.class)
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// Next state of each state and class, -1 when there is no transition\nvar transitions = [...]int32{")
+	writeInts(&sb, t.transitions)
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// Rule accepted by each state, -1 when it doesn't accept any\nvar acceptRules = [...]int32{")
+	writeInts(&sb, t.accept)
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// Writes the action of each rule as a case of a switch.
+func (t lexTables) writeActions() string {
+	rules := make([]int, 0, len(t.actions))
+	for rule := range t.actions {
+		rules = append(rules, rule)
+	}
+	sort.Ints(rules)
+
+	var sb strings.Builder
+	for _, rule := range rules {
+		code := strings.TrimSpace(t.actions[rule])
+		if code != "" {
+			code = code[1 : len(code)-1] // Remove the braces
+		}
+		fmt.Fprintf(&sb, "case %d:\n%s\nreturn SKIP_LEXEME\n", rule, code)
+	}
+	return sb.String()
+}
+
+func writeInts(sb *strings.Builder, values []int) {
+	for i, value := range values {
+		if i%32 == 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(strconv.Itoa(value))
+		sb.WriteString(", ")
+	}
+	if len(values) > 0 {
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,58 @@
+package Lex_writer
+
+import (
+	"fmt"
+	"testing"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	pf "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// [a-c]+ | x, where a and b go to the same states so they share a class
+func Test_buildTables(t *testing.T) {
+	q0 := &dfa.State{Id: "0", Transitions: make(map[dfa.Symbol]*dfa.State)}
+	q1 := &dfa.State{Id: "1", Transitions: make(map[dfa.Symbol]*dfa.State),
+		Actions: []dfa.Action{{Code: "{ return ID }", Priority: 0}}}
+	q2 := &dfa.State{Id: "2", Transitions: make(map[dfa.Symbol]*dfa.State),
+		Actions: []dfa.Action{{Code: "{ return X }", Priority: 1}}}
+
+	q0.Transitions["[a-b]"] = q1
+	q0.Transitions["c"] = q1
+	q0.Transitions["x"] = q2
+	q0.Transitions["ñ"] = q2
+	q1.Transitions["[a-b]"] = q1
+	q1.Transitions["10"] = q2 // Markers are not runes
+
+	automata := &dfa.DFA{
+		StartState: q0,
+		States:     []*dfa.State{q2, q1, q0},
+		Classes:    map[dfa.Symbol][]pf.RuneRange{"[a-b]": {{Lo: 'a', Hi: 'b'}}},
+	}
+
+	tables := buildTables(automata)
+	fmt.Println(tables.write())
+	fmt.Println(tables.writeActions())
+
+	// Classes: 0 none, 1 [a-b], 2 c, 3 x ñ
+	if tables.numClasses != 4 {
+		t.Fatalf("expected 4 classes, got %d", tables.numClasses)
+	}
+	if tables.ascii['a'] != 1 || tables.ascii['b'] != 1 || tables.ascii['c'] != 2 || tables.ascii['x'] != 3 || tables.ascii['z'] != 0 {
+		t.Errorf("unexpected ASCII classes %v", tables.ascii['a':'z'+1])
+	}
+	if len(tables.ranges) != 1 || tables.ranges[0].Lo != 'ñ' || tables.ranges[0].class != 3 {
+		t.Errorf("unexpected ranges %v", tables.ranges)
+	}
+
+	expected := []int{
+		-1, 1, 1, 2, // State 0 (start)
+		-1, 1, -1, -1, // State 1
+		-1, -1, -1, -1, // State 2
+	}
+	if fmt.Sprint(tables.transitions) != fmt.Sprint(expected) {
+		t.Errorf("expected transitions %v, got %v", expected, tables.transitions)
+	}
+	if fmt.Sprint(tables.accept) != fmt.Sprint([]int{-1, 0, 1}) {
+		t.Errorf("unexpected accepted rules %v", tables.accept)
+	}
+}
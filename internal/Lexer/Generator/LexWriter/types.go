@@ -1,10 +1,43 @@
 package Lex_writer
 
+import "fmt"
+
 // This module is in charge of writing the final Lexer.go file, based on a template file
 
 // Definition of variable fields withing a template
 type LexTemplate struct {
-	Header   string
-	Automata string
-	Footer   string
+	Header      string
+	DirectCoded bool   // Whether the DFA is written as code instead of tables
+	Tables      string // Equivalence classes, transitions and accepted rules of the DFA
+	Scanner     string // The DFA written as labeled blocks, only when DirectCoded
+	Keywords    string // Keyword tables and the keywordRule function
+	Actions     string // Cases of the switch that runs the action of each rule
+	Footer      string
+}
+
+// A literal rule left out of the DFA, it is found by looking up the lexemes
+// matched by its host rule. Ex: "while" on the lexemes of {id}
+type Keyword struct {
+	Text string
+	Rule int // Priority of the literal rule
+	Host int // Priority of the rule that matches the text on the DFA
+}
+
+// How the DFA is written on the generated lexer
+type EmitMode int
+
+const (
+	TABLE_DRIVEN EmitMode = iota // Flat tables walked by a loop
+	DIRECT_CODED                 // A labeled block per state joined by gotos
+)
+
+// Returns the emit mode for its name on the command line, "table" or "direct".
+func ParseEmitMode(name string) (EmitMode, error) {
+	switch name {
+	case "table":
+		return TABLE_DRIVEN, nil
+	case "direct":
+		return DIRECT_CODED, nil
+	}
+	return TABLE_DRIVEN, fmt.Errorf("unknown emit mode %q, expected table or direct", name)
 }
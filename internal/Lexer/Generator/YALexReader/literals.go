@@ -0,0 +1,162 @@
+package yalex_reader
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file gives quoted literals their meaning. Everything within "..." and
+// the single character within '...' is matched verbatim, so before the pattern
+// reaches the regex engine each of its characters is turned into an escaped symbol.
+
+// Decodes the escape sequence that starts at text[i] (a backslash). Supports:
+//
+//	\n \t \r \f \v \\ \" \'
+//	\xHH     hexadecimal byte
+//	\u{HHHH} unicode code point
+//
+// Any other escaped character stands for itself. Ex: \+ => +
+//
+// Returns the rune, the bytes consumed (including the backslash) and an
+// error message if the sequence is malformed.
+func decodeEscape(text string, i int) (rune, int, string) {
+	if i+1 >= len(text) {
+		return 0, 1, "escape symbol \\ without a character"
+	}
+
+	switch text[i+1] {
+	case 'n':
+		return '\n', 2, ""
+	case 't':
+		return '\t', 2, ""
+	case 'r':
+		return '\r', 2, ""
+	case 'f':
+		return '\f', 2, ""
+	case 'v':
+		return '\v', 2, ""
+	case 'x':
+		if i+4 > len(text) {
+			return 0, 2, "\\x escape needs 2 hexadecimal digits"
+		}
+		value, err := strconv.ParseUint(text[i+2:i+4], 16, 8)
+		if err != nil {
+			return 0, 2, "\\x escape needs 2 hexadecimal digits"
+		}
+		return rune(value), 4, ""
+	case 'u':
+		end := strings.IndexByte(text[i:], '}')
+		if i+2 >= len(text) || text[i+2] != '{' || end == -1 {
+			return 0, 2, "\\u escape must look like \\u{1F600}"
+		}
+		value, err := strconv.ParseUint(text[i+3:i+end], 16, 32)
+		if err != nil || value > unicode.MaxRune {
+			return 0, 2, "invalid code point on \\u escape"
+		}
+		return rune(value), end + 1, ""
+	}
+
+	r, size := utf8.DecodeRuneInString(text[i+1:])
+	return r, size + 1, ""
+}
+
+// Writes a rune so the regex engine reads it as a plain character. Letters and
+// digits are never operators so they are kept as they are.
+func writeLiteral(sb *strings.Builder, r rune) {
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		sb.WriteRune('\\')
+	}
+	sb.WriteRune(r)
+}
+
+// Translates the literals of an already validated pattern into escaped symbols:
+//
+//	"a+b"  => a\+b
+//	'\n'   => \<line break>
+//
+// Outside literals only the escapes of invisible characters (\n, \t, \x0A ...)
+// are decoded, the rest of the pattern is left untouched. Quotes within
+// classes are ordinary characters. Ex: ["'] matches both quotes.
+func lowerLiterals(pattern string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(pattern); {
+		switch pattern[i] {
+		case '"', '\'':
+			quote := pattern[i]
+			for i++; i < len(pattern) && pattern[i] != quote; {
+				r, size := utf8.DecodeRuneInString(pattern[i:])
+				if r == '\\' {
+					r, size, _ = decodeEscape(pattern, i)
+				}
+				writeLiteral(&sb, r)
+				i += size
+			}
+			i++ // closing quote
+
+		case '[':
+			end := classEnd(pattern, i)
+			if end == -1 {
+				end = len(pattern)
+			}
+			for i < end {
+				i += lowerSymbol(&sb, pattern, i)
+			}
+
+		default:
+			i += lowerSymbol(&sb, pattern, i)
+		}
+	}
+
+	return sb.String()
+}
+
+// Copies the symbol on pattern[i] decoding the escapes of invisible characters,
+// regex escapes like \+ or \( are kept as they are. Returns the bytes consumed.
+func lowerSymbol(sb *strings.Builder, pattern string, i int) int {
+	if pattern[i] != '\\' || i+1 >= len(pattern) {
+		r, size := utf8.DecodeRuneInString(pattern[i:])
+		sb.WriteRune(r)
+		return size
+	}
+
+	if strings.IndexByte("ntrfvxu", pattern[i+1]) != -1 {
+		r, size, _ := decodeEscape(pattern, i)
+		writeLiteral(sb, r)
+		return size
+	}
+
+	r, size := utf8.DecodeRuneInString(pattern[i+1:])
+	sb.WriteByte('\\')
+	sb.WriteRune(r)
+	return size + 1
+}
+
+// Literal returns the text matched by a rule whose pattern is a plain sequence
+// of characters, like "while" or "==". False for any other pattern, including
+// the caseless ones, since they match more than one text.
+func (rule YALexRule) Literal() (string, bool) {
+	if rule.Caseless || rule.Pattern == "" {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(rule.Pattern); {
+		r, size := utf8.DecodeRuneInString(rule.Pattern[i:])
+		if r == '\\' && i+size < len(rule.Pattern) {
+			// Escaped letters and digits are classes like \d or \w
+			r, size = utf8.DecodeRuneInString(rule.Pattern[i+1:])
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return "", false
+			}
+			size++
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", false
+		}
+		sb.WriteRune(r)
+		i += size
+	}
+	return sb.String(), true
+}
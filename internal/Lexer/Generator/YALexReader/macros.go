@@ -0,0 +1,167 @@
+package yalex_reader
+
+import (
+	"strings"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+)
+
+// Replaces every reference "{name}" on the rules with the named pattern it refers to.
+//
+// Named patterns can refer to each other in any order, they are expanded following
+// their dependencies (topological order) so nested references are always resolved.
+// Each expansion is wrapped in a group, "{a}*" with a = "x|y" becomes "(x|y)*".
+func expandRules(src *io.Source, rules []YALexRule, macros []namedPattern) error {
+	diagnostics := make(io.Diagnostics, 0)
+
+	byName := make(map[string]*namedPattern, len(macros))
+	for i := range macros {
+		byName[macros[i].Name] = &macros[i]
+	}
+
+	// Report undefined references before trying to expand anything
+	for _, macro := range macros {
+		diagnostics = append(diagnostics, undefinedReferences(src, macro.Pattern, macro.offset, byName)...)
+	}
+	for _, rule := range rules {
+		diagnostics = append(diagnostics, undefinedReferences(src, rule.Pattern, rule.offset, byName)...)
+	}
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+
+	order, err := sortMacros(src, macros, byName)
+	if err != nil {
+		return err
+	}
+
+	expanded := make(map[string]string, len(macros))
+	for _, macro := range order {
+		expanded[macro.Name] = expandReferences(macro.Pattern, expanded)
+	}
+
+	for i := range rules {
+		rules[i].Pattern = lowerLiterals(expandReferences(rules[i].Pattern, expanded))
+	}
+
+	return nil
+}
+
+// A "{name}" found within a pattern
+type reference struct {
+	name string
+	// Byte offsets of "{" and after "}" relative to the pattern
+	start, end int
+}
+
+// Finds all the references of a pattern. Escaped braces "\{" and braces within
+// classes "[{}]", literals "{a}" or unicode classes "\p{L}" are not references.
+func findReferences(pattern string) []reference {
+	references := make([]reference, 0)
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if end := propertyEnd(pattern, i); end != -1 {
+				i = end - 1
+			} else {
+				i++
+			}
+		case '[':
+			if end := classEnd(pattern, i); end != -1 {
+				i = end - 1
+			}
+		case '"', '\'':
+			quote := pattern[i]
+			for i++; i < len(pattern) && pattern[i] != quote; i++ {
+				if pattern[i] == '\\' {
+					i++
+				}
+			}
+		case '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end > 1 && isIdentifier(pattern[i+1:i+end]) {
+				references = append(references, reference{name: pattern[i+1 : i+end], start: i, end: i + end + 1})
+				i += end
+			}
+		}
+	}
+
+	return references
+}
+
+func undefinedReferences(src *io.Source, pattern string, offset int, macros map[string]*namedPattern) io.Diagnostics {
+	diagnostics := make(io.Diagnostics, 0)
+	for _, ref := range findReferences(pattern) {
+		if _, exist := macros[ref.name]; !exist {
+			diagnostics = append(diagnostics, src.Errorf(offset+ref.start, "undefined named pattern %s", ref.name))
+		}
+	}
+	return diagnostics
+}
+
+// Orders the named patterns so each one comes after all the patterns it refers to.
+// Returns an error if the references form a cycle.
+func sortMacros(src *io.Source, macros []namedPattern, byName map[string]*namedPattern) ([]*namedPattern, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(macros))
+	order := make([]*namedPattern, 0, len(macros))
+	path := make([]string, 0)
+
+	var visit func(macro *namedPattern) error
+	visit = func(macro *namedPattern) error {
+		switch state[macro.Name] {
+		case visited:
+			return nil
+		case visiting:
+			// Cut the path to show only the names that form the cycle
+			cycle := path
+			for i, name := range path {
+				if name == macro.Name {
+					cycle = path[i:]
+					break
+				}
+			}
+			cycle = append(cycle, macro.Name)
+			first := byName[cycle[0]]
+			return src.Errorf(first.offset, "named patterns refer to each other in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[macro.Name] = visiting
+		path = append(path, macro.Name)
+		for _, ref := range findReferences(macro.Pattern) {
+			if err := visit(byName[ref.name]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[macro.Name] = visited
+		order = append(order, macro)
+		return nil
+	}
+
+	for i := range macros {
+		if err := visit(&macros[i]); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Replaces the references of a pattern with already expanded patterns, each one
+// wrapped in parenthesis.
+func expandReferences(pattern string, expanded map[string]string) string {
+	var sb strings.Builder
+	last := 0
+	for _, ref := range findReferences(pattern) {
+		sb.WriteString(pattern[last:ref.start])
+		sb.WriteString("(" + expanded[ref.name] + ")")
+		last = ref.end
+	}
+	sb.WriteString(pattern[last:])
+	return sb.String()
+}
@@ -0,0 +1,397 @@
+package yalex_reader
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	postfix "github.com/DanielRasho/Parser/internal/Lexer/DFA/Postfix"
+)
+
+// This file contains the low level tools to move over a yalex file, the grammar
+// itself lives on yalexread.go
+
+type scanner struct {
+	src *io.Source
+	pos int // Byte offset of the next rune to read
+}
+
+func newScanner(src *io.Source) *scanner {
+	return &scanner{src: src}
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.src.Content)
+}
+
+// Returns the next rune without consuming it, 0 on EOF.
+func (s *scanner) peek() rune {
+	if s.eof() {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(s.src.Content[s.pos:])
+	return r
+}
+
+// Returns the rune after the next one without consuming anything, 0 on EOF.
+func (s *scanner) peekSecond() rune {
+	if s.eof() {
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(s.src.Content[s.pos:])
+	if s.pos+size >= len(s.src.Content) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(s.src.Content[s.pos+size:])
+	return r
+}
+
+func (s *scanner) next() rune {
+	if s.eof() {
+		return 0
+	}
+	r, size := utf8.DecodeRuneInString(s.src.Content[s.pos:])
+	s.pos += size
+	return r
+}
+
+func (s *scanner) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(s.src.Content[s.pos:], prefix)
+}
+
+func (s *scanner) errorf(offset int, format string, args ...any) *io.Diagnostic {
+	return s.src.Errorf(offset, format, args...)
+}
+
+// Skips white spaces, line breaks and comments ("//" and "/* */").
+func (s *scanner) skipBlank() error {
+	for !s.eof() {
+		if unicode.IsSpace(s.peek()) {
+			s.next()
+			continue
+		}
+		skipped, err := s.skipComment()
+		if err != nil {
+			return err
+		}
+		if !skipped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Skips white spaces and comments without jumping to the next line.
+func (s *scanner) skipInlineBlank() error {
+	for !s.eof() {
+		if r := s.peek(); r != '\n' && unicode.IsSpace(r) {
+			s.next()
+			continue
+		}
+		// Line comments stop right before the line break.
+		skipped, err := s.skipComment()
+		if err != nil {
+			return err
+		}
+		if !skipped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Consumes a comment if the scanner is placed on one.
+func (s *scanner) skipComment() (bool, error) {
+	if s.hasPrefix("//") {
+		for !s.eof() && s.peek() != '\n' {
+			s.next()
+		}
+		return true, nil
+	}
+	if s.hasPrefix("/*") {
+		start := s.pos
+		end := strings.Index(s.src.Content[s.pos+2:], "*/")
+		if end == -1 {
+			return false, s.errorf(start, "unterminated comment, missing */")
+		}
+		s.pos += end + 4
+		return true, nil
+	}
+	return false, nil
+}
+
+// Reads an identifier [a-zA-Z_][a-zA-Z0-9_]*, returns empty string if there is none.
+func (s *scanner) identifier() string {
+	start := s.pos
+	for !s.eof() {
+		r := s.peek()
+		if r == '_' || unicode.IsLetter(r) || (s.pos > start && unicode.IsDigit(r)) {
+			s.next()
+			continue
+		}
+		break
+	}
+	return s.src.Content[start:s.pos]
+}
+
+// Checks if the scanner is placed over a named pattern reference "{name}"
+func (s *scanner) atReference() bool {
+	rest := s.src.Content[s.pos:]
+	if !strings.HasPrefix(rest, "{") {
+		return false
+	}
+	end := strings.IndexByte(rest, '}')
+	return end > 1 && isIdentifier(rest[1:end])
+}
+
+func isIdentifier(text string) bool {
+	for i, r := range text {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return text != ""
+}
+
+// Returns the offset after the class "[...]" that starts on text[start], or -1 if the
+// class is not closed on the same line. POSIX classes "[:alpha:]" may be nested.
+func classEnd(text string, start int) int {
+	for i := start + 1; i < len(text) && text[i] != '\n'; i++ {
+		switch {
+		case text[i] == '\\':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				return -1
+			}
+			i++
+		case strings.HasPrefix(text[i:], "[:"):
+			end := strings.Index(text[i:], ":]")
+			if end == -1 || strings.Contains(text[i:i+end], "\n") {
+				return -1
+			}
+			i += end + 1
+		case text[i] == ']':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// Returns the offset after the unicode class "\p{...}" or "\P{...}" that starts on
+// text[start], or -1 if there is none.
+func propertyEnd(text string, start int) int {
+	if !strings.HasPrefix(text[start:], `\p{`) && !strings.HasPrefix(text[start:], `\P{`) {
+		return -1
+	}
+	end := strings.IndexAny(text[start:], "}\n")
+	if end == -1 || text[start+end] != '}' {
+		return -1
+	}
+	return start + end + 1
+}
+
+// Reads a regex pattern. A pattern ends on the first space, line break or comment
+// found outside strings "..." '...', classes [...] and groups (...). It also ends
+// before a "{" that is not a named pattern reference, since that is where actions begin.
+func (s *scanner) pattern() (string, error) {
+	start := s.pos
+	groups := make([]int, 0) // Offsets of the open parenthesis
+
+	for !s.eof() {
+		r := s.peek()
+		offset := s.pos
+
+		if r == '\n' || (len(groups) == 0 && unicode.IsSpace(r)) {
+			break
+		}
+		if len(groups) == 0 && (s.hasPrefix("//") || s.hasPrefix("/*")) {
+			break
+		}
+
+		switch r {
+		case '\\':
+			if s.pos+1 >= len(s.src.Content) || s.src.Content[s.pos+1] == '\n' {
+				return "", s.errorf(offset, "escape symbol \\ at the end of the pattern")
+			}
+			if err := s.patternEscape(); err != nil {
+				return "", err
+			}
+		case '"':
+			if _, err := s.delimited(r, "string"); err != nil {
+				return "", err
+			}
+		case '\'':
+			count, err := s.delimited(r, "character")
+			if err != nil {
+				return "", err
+			}
+			if count != 1 {
+				return "", s.errorf(offset, "a character literal must contain exactly one character, use \"...\" for strings")
+			}
+		case '[':
+			if err := s.class(); err != nil {
+				return "", err
+			}
+		case '(':
+			if s.peekSecond() == '?' {
+				return "", s.errorf(offset, "flags like (?i) are only allowed at the start of a rule")
+			}
+			groups = append(groups, offset)
+			s.next()
+		case ')':
+			if len(groups) == 0 {
+				return "", s.errorf(offset, "unbalanced ), there is no group to close")
+			}
+			groups = groups[:len(groups)-1]
+			s.next()
+		case '{':
+			if !s.atReference() {
+				if len(groups) == 0 {
+					return s.src.Content[start:s.pos], nil
+				}
+				return "", s.errorf(offset, "expected a named pattern reference like {name}, use \\{ to match a brace")
+			}
+			s.pos += strings.IndexByte(s.src.Content[s.pos:], '}') + 1
+		case '}':
+			return "", s.errorf(offset, "unexpected }, use \\} to match a brace")
+		default:
+			s.next()
+		}
+	}
+
+	if len(groups) > 0 {
+		return "", s.errorf(groups[len(groups)-1], "unbalanced (, the group is never closed")
+	}
+	return s.src.Content[start:s.pos], nil
+}
+
+// Consumes a sequence that starts on the current rune and ends on an unescaped
+// closing rune within the same line. Ex: "abc", [a-z]
+// Returns the number of characters found between the delimiters.
+func (s *scanner) delimited(closing rune, name string) (int, error) {
+	start := s.pos
+	count := 0
+	s.next()
+	for !s.eof() && s.peek() != '\n' {
+		if s.peek() == '\\' {
+			if s.pos+1 >= len(s.src.Content) || s.src.Content[s.pos+1] == '\n' {
+				break
+			}
+			if err := s.escape(); err != nil {
+				return 0, err
+			}
+			count++
+			continue
+		}
+		if s.next() == closing {
+			return count, nil
+		}
+		count++
+	}
+	return 0, s.errorf(start, "unterminated %s, missing %c", name, closing)
+}
+
+// Consumes a class "[...]" checking its escapes and the runes it stands for.
+func (s *scanner) class() error {
+	start := s.pos
+	end := classEnd(s.src.Content, start)
+	if end == -1 {
+		return s.errorf(start, "unterminated class, missing ]")
+	}
+	for s.pos < end {
+		if s.peek() != '\\' {
+			s.next()
+			continue
+		}
+		if err := s.patternEscape(); err != nil {
+			return err
+		}
+	}
+	if _, err := postfix.ClassRanges(lowerLiterals(s.src.Content[start:end])); err != nil {
+		return s.errorf(start, "%s", err)
+	}
+	return nil
+}
+
+// Consumes an escape found outside literals, where "\p{...}" stands for a unicode class.
+func (s *scanner) patternEscape() error {
+	if next := s.peekSecond(); next != 'p' && next != 'P' {
+		return s.escape()
+	}
+	end := propertyEnd(s.src.Content, s.pos)
+	if end == -1 {
+		end = s.pos + len(`\p`)
+	}
+	if _, err := postfix.ClassRanges(s.src.Content[s.pos:end]); err != nil {
+		return s.errorf(s.pos, "%s", err)
+	}
+	s.pos = end
+	return nil
+}
+
+// Consumes an escape sequence like \n, \x41 or \u{41}, reporting it if malformed.
+func (s *scanner) escape() error {
+	_, size, message := decodeEscape(s.src.Content, s.pos)
+	if message != "" {
+		return s.errorf(s.pos, "%s", message)
+	}
+	s.pos += size
+	return nil
+}
+
+// Reads an action "{ ... }" of Go code, including its braces. Braces within Go strings
+// and comments are not taken into account.
+func (s *scanner) action() (string, error) {
+	start := s.pos
+	depth := 0
+
+	for !s.eof() {
+		offset := s.pos
+		switch r := s.next(); r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s.src.Content[start:s.pos], nil
+			}
+		case '"', '\'':
+			if err := s.goLiteral(offset, r); err != nil {
+				return "", err
+			}
+		case '`':
+			end := strings.IndexByte(s.src.Content[s.pos:], '`')
+			if end == -1 {
+				return "", s.errorf(offset, "unterminated raw string in action")
+			}
+			s.pos += end + 1
+		case '/':
+			s.pos = offset
+			skipped, err := s.skipComment()
+			if err != nil {
+				return "", err
+			}
+			if !skipped {
+				s.next()
+			}
+		}
+	}
+
+	return "", s.errorf(start, "unterminated action, missing }")
+}
+
+// Consumes the rest of a Go string or rune literal.
+func (s *scanner) goLiteral(start int, closing rune) error {
+	for !s.eof() && s.peek() != '\n' {
+		r := s.next()
+		if r == '\\' {
+			s.next()
+			continue
+		}
+		if r == closing {
+			return nil
+		}
+	}
+	return s.errorf(start, "unterminated literal %c in action", closing)
+}
@@ -2,22 +2,46 @@
 // in the program.
 package yalex_reader
 
+import io "github.com/DanielRasho/Parser/internal/IO"
+
 /*
 PIPELINE
 	| PULL HEADER
 	| PULL PATTERNS
-	| PULL TOKENS
 	| PULL RULES
 	| PULL FOOTER
+	| EXPAND NAMED PATTERNS
 */
 
 type YALexDefinition struct {
 	Header string
 	Footer string
 	Rules  []YALexRule
+	// Set by "%option caseless", rules match letters regardless of their case
+	// unless they start with "(?-i)"
+	Caseless bool
 }
 
 type YALexRule struct {
 	Pattern string
-	Action  string
+	// The pattern as written on the file, before expanding its named patterns
+	Source string
+	Action string
+	// Letters are matched regardless of their case. Set by "(?i)" at the start of
+	// the pattern or by "%option caseless"
+	Caseless bool
+	// Where the rule was defined on the yalex file
+	Pos io.Position
+
+	offset int
+}
+
+// A named pattern (macro) defined on the "{ }" section of a yalex file. Ex:
+//
+//	digit [0-9]
+type namedPattern struct {
+	Name    string
+	Pattern string
+
+	offset int
 }
@@ -2,179 +2,308 @@ package yalex_reader
 
 import (
 	"strings"
+	"unicode"
 
 	io "github.com/DanielRasho/Parser/internal/IO"
 )
 
+// Parse reads a yalex file into a YALexDefinition. Any syntax error is
+// returned as an *io.Diagnostic pointing to the line and column where it was found.
 func Parse(filePath string) (*YALexDefinition, error) {
+	source, err := io.ReadSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSource(source)
+}
 
-	filereader, _ := io.ReadFile(filePath)
-	var line string
-
-	//Esto es utilizado para leer el cuerpo ya sea del header, footer, reglas
-	readinghead := false
-	readingrules := false
-	readingfooter := false
-	readingbody := false
-	metcond := true
-	metcond1 := true
-	counter := 0
-
-	var Header string
-	var Footer string
-	Rules := make([]string, 0)
-	Tokens := make([]string, 0)
-	hashtokens := make(map[string]string, 0)
-	YalRules := make([]YALexRule, 0)
-
-	for filereader.NextLine(&line) {
-		// Starting with header once it finds the end -->
-		if line == "%{\n" || readinghead {
-
-			if line == "%{\n" {
-				metcond = false
-			}
+// ParseSource reads a yalex definition already loaded in memory.
+//
+// The file is composed of the following sections, comments ("//", "/* */") can
+// be placed between any of them:
+//
+//	%{ header %}
+//	%option caseless
+//	{ named patterns }
+//	%% rules %%
+//	%{ footer %}
+func ParseSource(source *io.Source) (*YALexDefinition, error) {
+	s := newScanner(source)
+	definition := &YALexDefinition{Rules: make([]YALexRule, 0)}
+	macros := make([]namedPattern, 0)
+
+	readHeader, readMacros, readRules, readFooter := false, false, false, false
+
+	for {
+		if err := s.skipBlank(); err != nil {
+			return nil, err
+		}
+		if s.eof() {
+			break
+		}
+		start := s.pos
 
-			if line == "%}\n" {
-				metcond = false
+		switch {
+		case s.hasPrefix("%{"):
+			code, err := parseCode(s)
+			if err != nil {
+				return nil, err
+			}
+			if !readRules && !readHeader && !readMacros {
+				definition.Header = code
+				readHeader = true
+			} else if readRules && !readFooter {
+				definition.Footer = code
+				readFooter = true
+			} else {
+				return nil, s.errorf(start, "unexpected code section, only a header before the named patterns and a footer after the rules are allowed")
 			}
 
-			readinghead = true
-			if metcond {
-				Header = Header + line + "\n"
+		case s.hasPrefix("%option"):
+			if readRules {
+				return nil, s.errorf(start, "options must be set before the rules section")
 			}
-			//Ends it and dont have to add another line for header
-			if line == "%}\n" {
-				readinghead = false
+			if err := parseOptions(s, definition); err != nil {
+				return nil, err
 			}
-			metcond = true
-		}
 
-		//Patterns
-		// Body
-		if line == "{\n" || readingbody {
-			readingbody = true
-			Tokens = append(Tokens, line)
-			if line == "}\n" {
-				readingbody = false
+		case s.hasPrefix("%%"):
+			if readRules {
+				return nil, s.errorf(start, "rules section defined twice")
 			}
-
-		}
-
-		//Footer
-		if line == "%{\n" && !readinghead || readingfooter {
-			readingfooter = true
-			readinghead = false
-			metcond = false
-
-			if line == "%{\n" {
-				metcond1 = false
+			rules, err := parseRules(s, definition.Caseless)
+			if err != nil {
+				return nil, err
 			}
+			definition.Rules = rules
+			readRules = true
 
-			if line == "%}\n" {
-				metcond1 = false
+		case s.peek() == '{':
+			if readRules {
+				return nil, s.errorf(start, "named patterns must be defined before the rules section")
 			}
-
-			readinghead = true
-			if metcond1 {
-				Footer = Footer + line + "\n"
+			if readMacros {
+				return nil, s.errorf(start, "named patterns section defined twice")
 			}
-
-			if line == "%}\n" {
-				readingfooter = false
-
+			newMacros, err := parseMacros(s)
+			if err != nil {
+				return nil, err
 			}
-			metcond1 = true
+			macros = newMacros
+			readMacros = true
+
+		default:
+			return nil, s.errorf(start, "unexpected %q, expected a code section %%{, an %%option, named patterns { or rules %%%%", s.peek())
 		}
+	}
 
-		//Rules Rud
-		if line == "%%\n" || readingrules {
-			readingrules = true
+	if !readRules {
+		return nil, s.errorf(s.pos, "missing rules section, rules should be placed between %%%% and %%%%")
+	}
 
-			if strings.Compare(line, "\n") == 1 {
-				Rules = append(Rules, line)
-			}
+	if err := expandRules(source, definition.Rules, macros); err != nil {
+		return nil, err
+	}
 
-			//La linea que encuentra las reglas y sus bodies. "%%" si detecta otro entonces se cierra y no lee mas rules
-			if line == "%%\n" && counter > 0 {
-				readingrules = false
-				readingfooter = true
+	return definition, nil
+}
 
-			}
-			counter++
+// Reads a "%{ ... %}" code section. The closing "%}" must be the first thing on its line.
+func parseCode(s *scanner) (string, error) {
+	start := s.pos
+	s.pos += len("%{")
+
+	content := s.src.Content[s.pos:]
+	offset := 0
+	for {
+		lineEnd := strings.IndexByte(content[offset:], '\n')
+		line := content[offset:]
+		if lineEnd != -1 {
+			line = content[offset : offset+lineEnd]
+		}
+
+		if trimmed := strings.TrimLeft(line, " \t"); strings.HasPrefix(trimmed, "%}") {
+			code := content[:offset]
+			s.pos += offset + (len(line) - len(trimmed)) + len("%}")
+			return strings.TrimPrefix(code, "\n"), nil
 		}
 
+		if lineEnd == -1 {
+			return "", s.errorf(start, "unterminated code section, missing %%}")
+		}
+		offset += lineEnd + 1
 	}
+}
 
-	//Quitas las llaves
-	Tokens = Tokens[1 : len(Tokens)-1]
-	for i := range len(Tokens) {
-		//Espacios en blancos lineas en blanco
-		if strings.TrimSpace(Tokens[i]) != "" {
-
-			Tokens[i] = strings.Split(Tokens[i], "//")[0]
-			//Encuentra un comentario
-			if strings.TrimSpace(Tokens[i]) != "" {
-
-				//Esta seccion guarda la expresion regexp y la accion que se debe de tomar,
-				Tokens[i] = strings.TrimSpace(Tokens[i])
-				key := strings.SplitAfterN(Tokens[i], " ", 2)[0]
-				key = strings.TrimSpace(key)
-				key = "{" + key + "}"
-				value := strings.SplitAfterN(Tokens[i], " ", 2)[1]
-				value = strings.TrimSpace(value)
-				value = strings.ReplaceAll(value, `\t`, "\t") // Replace tab with space
-				value = strings.ReplaceAll(value, `\n`, "\n") // Replace newline with space
-				value = strings.ReplaceAll(value, `\r`, "\r") // Replace carriage return with space
-				for i, valor := range hashtokens {
-					value = strings.ReplaceAll(value, i, valor)
-				}
-
-				hashtokens[key] = value
-			}
+// Reads a "%option name ..." line. Supported options:
+//
+//	caseless, case-insensitive  letters of every rule match regardless of their case
+//	case-sensitive              (default) letters match only their own case
+func parseOptions(s *scanner, definition *YALexDefinition) error {
+	s.pos += len("%option")
 
+	for {
+		if err := s.skipInlineBlank(); err != nil {
+			return err
+		}
+		if s.eof() || s.peek() == '\n' {
+			return nil
 		}
 
+		start := s.pos
+		for !s.eof() && !unicode.IsSpace(s.peek()) {
+			s.next()
+		}
+		switch option := s.src.Content[start:s.pos]; option {
+		case "caseless", "case-insensitive":
+			definition.Caseless = true
+		case "case-sensitive":
+			definition.Caseless = false
+		default:
+			return s.errorf(start, "unknown option %s", option)
+		}
 	}
+}
 
-	//Solo quita las llaves {  }
-	Rules = Rules[1 : len(Rules)-1]
-	for i := range len(Rules) {
-		if strings.TrimSpace(Rules[i]) != "" {
-			yal := YALexRule{Pattern: "", Action: ""}
-			Rules[i] = strings.Split(Rules[i], "//")[0]
-			Rules[i] = strings.TrimSpace(Rules[i])
-			//Esta seccion guarda la expresion regexp y la accion que se debe de tomar,
-			key_change := strings.TrimSpace(strings.SplitAfterN(Rules[i], "  ", 2)[0])
-
-			pattern, exist := hashtokens[key_change]
-
-			if !exist {
-				pattern = key_change
-				if strings.Compare(pattern, "\"\"\"") != 0 {
-					pattern = strings.ReplaceAll(pattern, "\"", "")
-				} else {
-					pattern = pattern[1:]
-					pattern = pattern[:len(pattern)-1]
-				}
+// Reads a "{ ... }" named patterns section, each pattern is defined on its own line as:
+//
+//	name pattern
+//	let name = pattern
+func parseMacros(s *scanner) ([]namedPattern, error) {
+	start := s.pos
+	s.next() // "{"
+	macros := make([]namedPattern, 0)
+	defined := make(map[string]struct{})
+
+	for {
+		if err := s.skipBlank(); err != nil {
+			return nil, err
+		}
+		if s.eof() {
+			return nil, s.errorf(start, "unterminated named patterns section, missing }")
+		}
+		if s.peek() == '}' {
+			s.next()
+			return macros, nil
+		}
+
+		offset := s.pos
+		name := s.identifier()
+		if name == "" {
+			return nil, s.errorf(offset, "expected the name of a pattern, found %q", s.peek())
+		}
+
+		// Support the "let name = pattern" notation
+		if err := s.skipInlineBlank(); err != nil {
+			return nil, err
+		}
+		if name == "let" && s.peek() != '=' {
+			offset = s.pos
+			if name = s.identifier(); name == "" {
+				return nil, s.errorf(offset, "expected the name of a pattern after let")
+			}
+			if err := s.skipInlineBlank(); err != nil {
+				return nil, err
 			}
+		}
+		if s.peek() == '=' {
+			s.next()
+			if err := s.skipInlineBlank(); err != nil {
+				return nil, err
+			}
+		}
 
-			yal.Pattern = pattern
-			yal.Action = strings.TrimSpace(strings.SplitAfterN(Rules[i], "  ", 2)[1])
+		if _, exist := defined[name]; exist {
+			return nil, s.errorf(offset, "pattern %s is already defined", name)
+		}
 
-			YalRules = append(YalRules, yal)
+		patternStart := s.pos
+		pattern, err := s.pattern()
+		if err != nil {
+			return nil, err
+		}
+		if pattern == "" {
+			return nil, s.errorf(patternStart, "expected a pattern for %s", name)
 		}
 
+		if err := s.skipInlineBlank(); err != nil {
+			return nil, err
+		}
+		if !s.eof() && s.peek() != '\n' && s.peek() != '}' {
+			return nil, s.errorf(s.pos, "unexpected %q after pattern %s, spaces are only allowed inside strings, classes or groups", s.peek(), name)
+		}
+
+		defined[name] = struct{}{}
+		macros = append(macros, namedPattern{
+			Name:    name,
+			Pattern: pattern,
+			offset:  patternStart,
+		})
 	}
+}
 
-	filereader.Close()
+// Reads the "%% ... %%" rules section, each rule is composed of a pattern and
+// an action of Go code:
+//
+//	pattern	{ action }
+//
+// A pattern starting with "(?i)" matches letters regardless of their case, "(?-i)"
+// turns it off when "%option caseless" is set.
+//
+// The closing "%%" may be omitted if nothing follows the rules.
+func parseRules(s *scanner, caseless bool) ([]YALexRule, error) {
+	s.pos += len("%%")
+	rules := make([]YALexRule, 0)
+
+	for {
+		if err := s.skipBlank(); err != nil {
+			return nil, err
+		}
+		if s.eof() {
+			return rules, nil
+		}
+		if s.hasPrefix("%%") {
+			s.pos += len("%%")
+			return rules, nil
+		}
 
-	yalexdef := YALexDefinition{
-		Header: Header,
-		Footer: Footer,
-		Rules:  YalRules,
-	}
+		offset := s.pos
+		ruleCaseless := caseless
+		if s.hasPrefix("(?i)") {
+			ruleCaseless = true
+			s.pos += len("(?i)")
+		} else if s.hasPrefix("(?-i)") {
+			ruleCaseless = false
+			s.pos += len("(?-i)")
+		}
 
-	return &yalexdef, nil
+		patternStart := s.pos
+		pattern, err := s.pattern()
+		if err != nil {
+			return nil, err
+		}
+		if pattern == "" {
+			return nil, s.errorf(patternStart, "expected a pattern, found %q", s.peek())
+		}
 
+		if err := s.skipBlank(); err != nil {
+			return nil, err
+		}
+		if s.peek() != '{' {
+			return nil, s.errorf(s.pos, "expected an action { ... } after pattern %s", pattern)
+		}
+		action, err := s.action()
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, YALexRule{
+			Pattern:  pattern,
+			Source:   pattern,
+			Action:   action,
+			Caseless: ruleCaseless,
+			Pos:      s.src.Position(offset),
+			offset:   patternStart,
+		})
+	}
 }
@@ -3,14 +3,21 @@ package yalex_reader
 // Aceptar cualquier caracter
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
 )
 
 // Se inicializa la tabla y se revisa si el mapa tiene un estado y verificar si ese estado es final
 func Test_check_DFA(t *testing.T) {
 
-	Yalexdef, _ := Parse("../../../examples/example0.lex")
+	Yalexdef, err := Parse("../../../../examples/simple.lex")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	println("\nFooter\n")
 	fmt.Println(Yalexdef.Footer)
@@ -26,3 +33,215 @@ func Test_check_DFA(t *testing.T) {
 	}
 
 }
+
+func Test_examples(t *testing.T) {
+	examples := []string{"superSimple", "simple", "medium", "hard", "hard2"}
+
+	for _, example := range examples {
+		definition, err := Parse("../../../../examples/" + example + ".lex")
+		if err != nil {
+			t.Errorf("%s: %v", example, err)
+			continue
+		}
+		if len(definition.Rules) == 0 {
+			t.Errorf("%s: no rules were read", example)
+		}
+	}
+}
+
+func Test_flexibleLayout(t *testing.T) {
+	content := "/* header */ %{\r\nconst ( A = iota )\r\n%}\r\n" +
+		"{ let digit = [0-9]   // comment\r\n  number {digit}+ }\r\n" +
+		"%%\r\n" +
+		"{number}\t{ return A } /* trailing */\r\n" +
+		"\"a b\"{\n  if x := \"}\"; x != \"\" { return A }\n}\r\n" +
+		"%%"
+
+	definition, err := ParseSource(io.NewSource("test.lex", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if definition.Header != "const ( A = iota )\n" {
+		t.Errorf("unexpected header %q", definition.Header)
+	}
+	if len(definition.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(definition.Rules))
+	}
+	if definition.Rules[0].Pattern != "(([0-9])+)" {
+		t.Errorf("unexpected pattern %q", definition.Rules[0].Pattern)
+	}
+	if !strings.HasSuffix(definition.Rules[1].Action, "return A }\n}") {
+		t.Errorf("unexpected action %q", definition.Rules[1].Action)
+	}
+	if definition.Rules[1].Pos.Line != 8 || definition.Rules[1].Pos.Column != 1 {
+		t.Errorf("unexpected position %v", definition.Rules[1].Pos)
+	}
+}
+
+func Test_diagnostics(t *testing.T) {
+	cases := []struct {
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{"{\n digit [0-9\n}\n%%\n%%", 2, 8, "unterminated class"},
+		{"%%\n\"a\" { return A\n", 2, 5, "unterminated action"},
+		{"%%\n\"a\"\n\"b\" { }", 3, 1, "expected an action"},
+		{"{\n id (a|b\n}\n%%\n%%", 2, 5, "unbalanced ("},
+		{"%{\nconst A = 1\n", 1, 1, "unterminated code section"},
+		{"{\n digit [0-9]\n}", 3, 2, "missing rules section"},
+		{"{\n digit [0-9]\n digit [a-z]\n}\n%%\n%%", 3, 2, "already defined"},
+		{"%%\n\"a\" { } /* open", 2, 9, "unterminated comment"},
+		{"%%\n'ab' { }", 2, 1, "exactly one character"},
+		{"%%\n\"a\\x4\" { }", 2, 3, "\\x escape"},
+		{"%%\n\"\\u{zz}\" { }", 2, 2, "invalid code point"},
+		{"%%\na\\p{Klingon} { }", 2, 2, "unknown unicode class"},
+		{"%%\n[[:letter:]] { }", 2, 1, "unknown POSIX class"},
+		{"%%\n[z-a] { }", 2, 1, "invalid range"},
+	}
+
+	for _, c := range cases {
+		_, err := ParseSource(io.NewSource("test.lex", c.content))
+		if err == nil {
+			t.Errorf("expected error %q for %q", c.message, c.content)
+			continue
+		}
+		var diagnostic *io.Diagnostic
+		if !errors.As(err, &diagnostic) {
+			t.Errorf("expected a diagnostic, got %v", err)
+			continue
+		}
+		if diagnostic.Pos.Line != c.line || diagnostic.Pos.Column != c.column ||
+			!strings.Contains(diagnostic.Message, c.message) {
+			t.Errorf("expected %d:%d %q, got:\n%v", c.line, c.column, c.message, err)
+		}
+	}
+}
+
+func Test_namedPatterns(t *testing.T) {
+	content := "{\n" +
+		" id     {letter}({letter}|{digit})*\n" +
+		" ab     a|b\n" +
+		" letter [a-z]\n" +
+		" digit  [0-9]\n" +
+		"}\n%%\n" +
+		"{id}    { return ID }\n" +
+		"{ab}*   { return AB }\n" +
+		"[{}]    { return BRACE }\n" +
+		"%%"
+
+	definition, err := ParseSource(io.NewSource("test.lex", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"(([a-z])(([a-z])|([0-9]))*)",
+		"(a|b)*",
+		"[{}]",
+	}
+	for i, rule := range definition.Rules {
+		if rule.Pattern != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], rule.Pattern)
+		}
+	}
+}
+
+func Test_namedPatternsErrors(t *testing.T) {
+	cases := []struct {
+		content string
+		message string
+	}{
+		{"{\n a {b}x\n b {c}\n c {a}\n}\n%%\n{a} { }", "test.lex:2:4: named patterns refer to each other in a cycle: a -> b -> c -> a"},
+		{"{\n a a{a}\n}\n%%\n{a} { }", "test.lex:2:4: named patterns refer to each other in a cycle: a -> a"},
+		{"{\n a [0-9]{digits}\n}\n%%\n{a} { }", "test.lex:2:9: undefined named pattern digits"},
+		{"{\n a [0-9]\n}\n%%\n{a}{b} { }", "test.lex:5:4: undefined named pattern b"},
+	}
+
+	for _, c := range cases {
+		_, err := ParseSource(io.NewSource("test.lex", c.content))
+		if err == nil || !strings.HasPrefix(err.Error(), c.message) {
+			t.Errorf("expected %q, got %v", c.message, err)
+		}
+	}
+}
+
+func Test_literals(t *testing.T) {
+	content := "{\n" +
+		" id [a-z]\n" +
+		"}\n%%\n" +
+		"\"a+b\"          { return PLUS }\n" +
+		"'\"'{id}'\"'       { return STRING }\n" +
+		"\"{id}\"         { return NOT_A_REFERENCE }\n" +
+		"\"\\t\\x41\\u{e9}\"  { return ESCAPES }\n" +
+		"[\"']+           { return QUOTES }\n" +
+		"[[:alpha:]\"]\\p{L} { return CLASSES }\n" +
+		"%%"
+
+	definition, err := ParseSource(io.NewSource("test.lex", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`a\+b`,
+		`\"([a-z])\"`,
+		`\{id\}`,
+		"\\\tAé",
+		`["']+`,
+		`[[:alpha:]"]\p{L}`,
+	}
+	for i, rule := range definition.Rules {
+		if rule.Pattern != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], rule.Pattern)
+		}
+	}
+
+	// Only plain sequences of characters are literals
+	literals := []string{"a+b", "", "{id}", "\tAé", "", ""}
+	for i, rule := range definition.Rules {
+		text, ok := rule.Literal()
+		if ok != (literals[i] != "") || text != literals[i] {
+			t.Errorf("expected literal %q, got %q (%t)", literals[i], text, ok)
+		}
+	}
+}
+
+func Test_caseless(t *testing.T) {
+	content := "%option caseless\n" +
+		"%%\n" +
+		"\"select\"      { return SELECT }\n" +
+		"(?-i)[a-z]+   { return ID }\n" +
+		"%%"
+
+	definition, err := ParseSource(io.NewSource("test.lex", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !definition.Caseless || !definition.Rules[0].Caseless || definition.Rules[1].Caseless {
+		t.Errorf("unexpected caseless flags %v", definition)
+	}
+	if definition.Rules[1].Pattern != "[a-z]+" || definition.Rules[1].Pos.Column != 1 {
+		t.Errorf("unexpected rule %v", definition.Rules[1])
+	}
+
+	definition, err = ParseSource(io.NewSource("test.lex", "%%\n(?i)\"from\" { }\n\"to\" { }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !definition.Rules[0].Caseless || definition.Rules[1].Caseless {
+		t.Errorf("unexpected caseless flags %v", definition.Rules)
+	}
+
+	for content, message := range map[string]string{
+		"%option shouting\n%%\n": "test.lex:1:9: unknown option shouting",
+		"%%\na(?i)b { }":         "test.lex:2:2: flags like (?i) are only allowed at the start of a rule",
+	} {
+		_, err := ParseSource(io.NewSource("test.lex", content))
+		if err == nil || !strings.HasPrefix(err.Error(), message) {
+			t.Errorf("expected %q, got %v", message, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 
 	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
@@ -12,7 +13,8 @@ import (
 )
 
 // Given a file to read and a output path, writes a lexer definition to the desired path.
-func Compile(filePath, outputPath string, showLogs bool, renderDiagrams bool) error {
+// The mode chooses whether the DFA is written as tables or as direct code.
+func Compile(filePath, outputPath string, showLogs bool, renderDiagrams bool, mode Lex_writer.EmitMode) error {
 
 	// Parse Yalex file definition
 	yalexDefinition, err := yalex_reader.Parse(filePath)
@@ -20,10 +22,85 @@ func Compile(filePath, outputPath string, showLogs bool, renderDiagrams bool) er
 		return err
 	}
 
-	// Join all rules in a single regex expression alongside its special symbol
+	// Keywords are looked up after their host rule matches, so they are left
+	// out of the DFA
+	keywords, err := findKeywords(yalexDefinition)
+	if err != nil {
+		return err
+	}
+	skip := make(map[int]bool, len(keywords))
+	for _, keyword := range keywords {
+		skip[keyword.Rule] = true
+		if showLogs {
+			fmt.Printf("Keyword %q is looked up after rule %d\n", keyword.Text, keyword.Host)
+		}
+	}
+
+	rawExpresion, err := joinRules(yalexDefinition.Rules, skip)
+	if err != nil {
+		return err
+	}
+
+	if showLogs {
+		for _, v := range rawExpresion {
+			fmt.Print(v.Value)
+		}
+		fmt.Println("")
+	}
+
+	// Generate DFA for language recognition
+	automata, numFinalSymbols, err := dfa.NewDFA(rawExpresion, showLogs, renderDiagrams)
+	if err != nil {
+		return err
+	}
+	dfa.PrintDFA(automata)
+
+	// Warn about rules that will not behave as the user expects, overlaps are
+	// common (keywords and identifiers) so they are only shown along the logs.
+	patterns := make([]string, len(yalexDefinition.Rules))
+	for i, rule := range yalexDefinition.Rules {
+		patterns[i] = rule.Source
+	}
+	for _, warning := range dfa.AnalyzeRules(automata, len(yalexDefinition.Rules)) {
+		if warning.Problem == dfa.OVERLAPPING_RULES && !showLogs || skip[warning.Rule] {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: warning: %s\n", yalexDefinition.Rules[warning.Rule].Pos, warning.Describe(patterns))
+	}
+
+	//Despues de minimize
+	dfa.RemoveAbsortionStates(automata, numFinalSymbols) //Destructive operation
+
+	if renderDiagrams {
+		dfa.RenderDFA(automata, "./diagrams/automataFinal.png")
+	}
+
+	lextemp := Lex_writer.CreateLexTemplateComponentes(yalexDefinition, automata, keywords, mode)
+	Lex_writer.FillwithTemplate("./template/LexTemplate.go", lextemp, outputPath)
+
+	return nil
+}
+
+// Builds the DFA of every rule of a yalex definition, without leaving keywords out.
+func BuildDFA(yalexDefinition *yalex_reader.YALexDefinition) (*dfa.DFA, error) {
+	rawExpresion, err := joinRules(yalexDefinition.Rules, nil)
+	if err != nil {
+		return nil, err
+	}
+	automata, _, err := dfa.NewDFA(rawExpresion, false, false)
+	return automata, err
+}
+
+// Join all rules in a single regex expression alongside its special symbol,
+// leaving out the rules to skip.
+func joinRules(rules []yalex_reader.YALexRule, skip map[int]bool) ([]pf.RawSymbol, error) {
 	rawExpresion := make([]pf.RawSymbol, 0)
 
-	for index, rule := range yalexDefinition.Rules {
+	for index, rule := range rules {
+		if skip[index] {
+			continue
+		}
+
 		// For special tokens (the ones encapsulating actionable code)
 		// to be diferentiable they must:
 		// 	- Have more than 1 char
@@ -34,7 +111,12 @@ func Compile(filePath, outputPath string, showLogs bool, renderDiagrams bool) er
 
 		ok, _ := balancer.IsBalanced(rule.Pattern)
 		if !ok {
-			return fmt.Errorf("rule %s, has an unbalanced pattern", rule.Pattern)
+			return nil, fmt.Errorf("rule %s, has an unbalanced pattern", rule.Pattern)
+		}
+
+		if len(rawExpresion) > 0 {
+			rawExpresion = append(rawExpresion,
+				pf.RawSymbol{Value: "|", Action: pf.Action{Priority: pf.NULL_ACTION_PRIORITY}})
 		}
 
 		rawExpresion = append(rawExpresion, pf.
@@ -42,7 +124,8 @@ func Compile(filePath, outputPath string, showLogs bool, renderDiagrams bool) er
 		for _, r := range rule.Pattern {
 			rawExpresion = append(rawExpresion, pf.RawSymbol{
 				Value:  string(r),
-				Action: pf.Action{Priority: pf.NULL_ACTION_PRIORITY}})
+				Action: pf.Action{Priority: pf.NULL_ACTION_PRIORITY},
+				Fold:   rule.Caseless})
 		}
 		rawExpresion = append(rawExpresion,
 			pf.RawSymbol{Value: ")", Action: pf.Action{Priority: pf.NULL_ACTION_PRIORITY}})
@@ -51,37 +134,7 @@ func Compile(filePath, outputPath string, showLogs bool, renderDiagrams bool) er
 			Action: pf.Action{
 				Priority: index,
 				Code:     rule.Action}})
-
-		if index != len(yalexDefinition.Rules)-1 {
-			rawExpresion = append(rawExpresion,
-				pf.RawSymbol{Value: "|", Action: pf.Action{Priority: pf.NULL_ACTION_PRIORITY}})
-		}
-
 	}
 
-	if showLogs {
-		for _, v := range rawExpresion {
-			fmt.Print(v.Value)
-		}
-		fmt.Println("")
-	}
-
-	// Generate DFA for language recognition
-	automata, numFinalSymbols, err := dfa.NewDFA(rawExpresion, showLogs, renderDiagrams)
-	if err != nil {
-		return err
-	}
-	dfa.PrintDFA(automata)
-
-	//Despues de minimize
-	dfa.RemoveAbsortionStates(automata, numFinalSymbols) //Destructive operation
-
-	if renderDiagrams {
-		dfa.RenderDFA(automata, "./diagrams/automataFinal.png")
-	}
-
-	lextemp := Lex_writer.CreateLexTemplateComponentes(yalexDefinition, automata)
-	Lex_writer.FillwithTemplate("./template/LexTemplate.go", lextemp, outputPath)
-
-	return nil
+	return rawExpresion, nil
 }
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	Lex_writer "github.com/DanielRasho/Parser/internal/Lexer/Generator/LexWriter"
+	yalex_reader "github.com/DanielRasho/Parser/internal/Lexer/Generator/YALexReader"
+)
+
+// Finds the literal rules that can be left out of the DFA. A literal is a keyword
+// when a later rule that isn't a literal matches its text, like "while" and {id}:
+// since the literal comes first it always wins over that rule (its host), so
+// looking it up on the lexemes of the host gives the same tokens.
+//
+// Literals with the same text as another literal are kept on the DFA, they
+// decide between themselves by priority.
+func findKeywords(yal *yalex_reader.YALexDefinition) ([]Lex_writer.Keyword, error) {
+	literals := make(map[int]string)
+	count := make(map[string]int)
+	others := make(map[int]bool) // Rules that are not literals
+	for index, rule := range yal.Rules {
+		text, isLiteral := rule.Literal()
+		if !isLiteral {
+			others[index] = true
+			continue
+		}
+		literals[index] = text
+		count[text]++
+	}
+	if len(literals) == 0 || len(others) == 0 {
+		return nil, nil
+	}
+
+	// The DFA without literals tells which rule each text falls to
+	skip := make(map[int]bool, len(literals))
+	for index := range literals {
+		skip[index] = true
+	}
+	rawExpresion, err := joinRules(yal.Rules, skip)
+	if err != nil {
+		return nil, err
+	}
+	automata, _, err := dfa.NewDFA(rawExpresion, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	keywords := make([]Lex_writer.Keyword, 0)
+	for index := range yal.Rules {
+		text, isLiteral := literals[index]
+		if !isLiteral || count[text] > 1 {
+			continue
+		}
+		if host, matched := automata.Match(text); matched && host > index {
+			keywords = append(keywords, Lex_writer.Keyword{Text: text, Rule: index, Host: host})
+		}
+	}
+	return keywords, nil
+}
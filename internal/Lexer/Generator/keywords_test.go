@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	yalex_reader "github.com/DanielRasho/Parser/internal/Lexer/Generator/YALexReader"
+)
+
+func Test_findKeywords(t *testing.T) {
+	content := "%%\n" +
+		"\"if\"      { return IF }\n" +
+		"\"==\"      { return EQ }\n" + // No other rule matches it
+		"\"let\"     { return LET }\n" +
+		"\"let\"     { return LET_AGAIN }\n" + // Same text, both stay on the DFA
+		"[a-z]+    { return ID }\n" +
+		"[0-9]+    { return NUMBER }\n" +
+		"\"42\"      { return ANSWER }\n" + // NUMBER always wins
+		"%%"
+
+	definition, err := yalex_reader.ParseSource(io.NewSource("test.lex", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keywords, err := findKeywords(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(keywords) != `[{if 0 4}]` {
+		t.Errorf("unexpected keywords %v", keywords)
+	}
+}
@@ -0,0 +1,281 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Lex_writer "github.com/DanielRasho/Parser/internal/Lexer/Generator/LexWriter"
+)
+
+// Main of the generated lexers under test. It lexes its argument as the input
+// "main.txt", after calling the setup function of the footer, and prints every
+// token with its position followed by the errors collected on recovery mode.
+const driver = `package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	lexer, err := NewLexerFromReader("main.txt", strings.NewReader(os.Args[1]))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer lexer.Close()
+	setup(lexer)
+
+	for {
+		token, err := lexer.GetNextToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		fmt.Printf("%s:%d:%d %d %q\n", token.File, token.Line, token.Column, token.TokenID, token.Value)
+	}
+	for _, err := range lexer.Errors() {
+		if notFound, ok := err.(*PatternNotFound); ok {
+			fmt.Printf("not found %s:%d:%d %q\n", notFound.File, notFound.Line, notFound.Column, notFound.Pattern)
+		} else {
+			fmt.Println("collected:", err)
+		}
+	}
+}
+`
+
+// Both ways of writing the DFA must give the same tokens.
+var emitModes = []struct {
+	name string
+	mode Lex_writer.EmitMode
+}{
+	{"table", Lex_writer.TABLE_DRIVEN},
+	{"direct", Lex_writer.DIRECT_CODED},
+}
+
+// Runs a test on a lexer written on every emit mode.
+func forEachMode(t *testing.T, test func(t *testing.T, mode Lex_writer.EmitMode)) {
+	if testing.Short() {
+		t.Skip("builds a generated lexer")
+	}
+	for _, m := range emitModes {
+		t.Run(m.name, func(t *testing.T) {
+			test(t, m.mode)
+		})
+	}
+}
+
+// Generates a lexer from a yalex definition, whose footer must define
+// setup(l *Lexer), and returns what the driver prints for the input.
+func runLexer(t *testing.T, mode Lex_writer.EmitMode, definition string, input string) string {
+	t.Helper()
+	dir := t.TempDir()
+	lexFile := filepath.Join(dir, "test.lex")
+	if err := os.WriteFile(lexFile, []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Compile reads the template from the root of the repository
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatal(err)
+	}
+	err = Compile(lexFile, filepath.Join(dir, "lexer.go"), false, false, mode)
+	os.Chdir(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"go.mod":  "module lexertest\n\ngo 1.23\n",
+		"main.go": driver,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("go", "run", ".", input)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, output)
+	}
+	return string(output)
+}
+
+func expectOutput(t *testing.T, output string, expected []string) {
+	t.Helper()
+	if strings.TrimSpace(output) != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), output)
+	}
+}
+
+const numbers = `%{
+const (
+	INT = iota
+	FLOAT
+	DOT
+	ID
+)
+%}
+%%
+[0-9]+            { return INT }
+[0-9]+"."[0-9]+   { return FLOAT }
+"."               { return DOT }
+[a-z]+            { return ID }
+[ \n]+            { }
+%%
+`
+
+// The automata reads "12." looking for a FLOAT, gets stuck on x and goes back
+// to the INT it accepted before the dot.
+func Test_maximalMunch(t *testing.T) {
+	footer := "%{\nfunc setup(l *Lexer) {}\n%}\n"
+	forEachMode(t, func(t *testing.T, mode Lex_writer.EmitMode) {
+		expectOutput(t, runLexer(t, mode, numbers+footer, "12.x 3.5"), []string{
+			`main.txt:1:1 0 "12"`,
+			`main.txt:1:3 2 "."`,
+			`main.txt:1:4 3 "x"`,
+			`main.txt:1:6 1 "3.5"`,
+		})
+	})
+}
+
+// Every rune no rule starts with becomes its own ERROR_LEXEME token, the lexer
+// goes on after it and keeps the error with its position.
+func Test_recovery(t *testing.T) {
+	footer := "%{\nfunc setup(l *Lexer) { l.EnableRecovery() }\n%}\n"
+	forEachMode(t, func(t *testing.T, mode Lex_writer.EmitMode) {
+		expectOutput(t, runLexer(t, mode, numbers+footer, "1 @ 2\n#ñx"), []string{
+			`main.txt:1:1 0 "1"`,
+			`main.txt:1:3 -3 "@"`,
+			`main.txt:1:5 0 "2"`,
+			`main.txt:2:1 -3 "#"`,
+			`main.txt:2:2 -3 "ñ"`,
+			`main.txt:2:3 3 "x"`,
+			`not found main.txt:1:3 "@"`,
+			`not found main.txt:2:1 "#"`,
+			`not found main.txt:2:2 "ñ"`,
+		})
+	})
+}
+
+// "if" and "else" are matched by the identifiers, so they are looked up on the
+// keyword table once an identifier is recognized.
+const keywordRules = `%{
+const (
+	IF = iota
+	ELSE
+	ID
+	INT
+)
+%}
+%%
+"if"      { return IF }
+"else"    { return ELSE }
+[a-z]+    { return ID }
+[0-9]+    { return INT }
+[ \n]+    { }
+%%
+%{
+func setup(l *Lexer) { l.EnableRecovery() }
+%}
+`
+
+func Test_keywordsRecovery(t *testing.T) {
+	forEachMode(t, func(t *testing.T, mode Lex_writer.EmitMode) {
+		expectOutput(t, runLexer(t, mode, keywordRules, "if iff @else\nelses i 7$"), []string{
+			`main.txt:1:1 0 "if"`,
+			`main.txt:1:4 2 "iff"`,
+			`main.txt:1:8 -3 "@"`,
+			`main.txt:1:9 1 "else"`,
+			`main.txt:2:1 2 "elses"`,
+			`main.txt:2:7 2 "i"`,
+			`main.txt:2:9 3 "7"`,
+			`main.txt:2:10 -3 "$"`,
+			`not found main.txt:1:8 "@"`,
+			`not found main.txt:2:10 "$"`,
+		})
+	})
+}
+
+// The tables and the direct coded lexer of hard2.lex read the same tokens from
+// hard2.code.
+func Test_sameTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generated lexer")
+	}
+	definition, err := os.ReadFile("../../../examples/hard2.lex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := os.ReadFile("../../../examples/hard2.code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The footer of the example is replaced by one with the setup of the driver
+	withSetup := string(definition)
+	withSetup = withSetup[:strings.LastIndex(withSetup, "%{")] + "%{\nfunc setup(l *Lexer) { l.EnableRecovery() }\n%}\n"
+
+	table := runLexer(t, Lex_writer.TABLE_DRIVEN, withSetup, string(input))
+	direct := runLexer(t, Lex_writer.DIRECT_CODED, withSetup, string(input))
+	if tokens := strings.Count(table, "\n"); tokens < 50 {
+		t.Fatalf("expected the tokens of hard2.code, got %d lines:\n%s", tokens, table)
+	}
+	if table != direct {
+		t.Errorf("the lexers read different tokens\ntable:\n%s\ndirect:\n%s", table, direct)
+	}
+}
+
+// "@name" includes the input called name, "stop" ends the lexer once read.
+const includes = `%{
+const (
+	ID = iota
+)
+%}
+%%
+"@"[a-z]+   { l.PushInput(l.Text()[1:], strings.NewReader(files[l.Text()[1:]])) }
+[a-z]+      { return ID }
+[ \n]+      { }
+%%
+%{
+var files = map[string]string{
+	"inc":  "x\ny",
+	"stop": "z",
+}
+
+func setup(l *Lexer) {
+	l.SetEOFHook(func(l *Lexer, name string) bool {
+		fmt.Println("end of", name)
+		return name != "stop"
+	})
+}
+%}
+`
+
+// Tokens of a pushed input carry its name and their line within it, then the
+// parent resumes after the include, until the hook stops at the end of "stop".
+func Test_includes(t *testing.T) {
+	forEachMode(t, func(t *testing.T, mode Lex_writer.EmitMode) {
+		expectOutput(t, runLexer(t, mode, includes, "a @inc b\n@stop c"), []string{
+			`main.txt:1:1 0 "a"`,
+			`inc:1:1 0 "x"`,
+			`inc:2:1 0 "y"`,
+			`end of inc`,
+			`main.txt:1:8 0 "b"`,
+			`stop:1:1 0 "z"`,
+			`end of stop`,
+		})
+	})
+}
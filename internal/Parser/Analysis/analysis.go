@@ -0,0 +1,229 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+)
+
+// Analyze checks a grammar for symbols and productions that take no part in
+// its sentences. Warnings come grouped by kind, in the order of the Kind
+// constants, and in the order of definition within a kind.
+func Analyze(def *parser.ParserDefinition) []Warning {
+	warnings := make([]Warning, 0)
+	warnings = append(warnings, unreachable(def)...)
+	warnings = append(warnings, unproductive(def)...)
+	warnings = append(warnings, unusedTokens(def)...)
+	warnings = append(warnings, duplicates(def)...)
+	warnings = append(warnings, unitCycles(def)...)
+	return warnings
+}
+
+// Non terminals the start symbol, head of the first production, never derives.
+func unreachable(def *parser.ParserDefinition) []Warning {
+	start := def.Productions[0].Head.Value
+	reached := map[string]bool{start: true}
+	pending := []string{start}
+	for len(pending) > 0 {
+		head := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, production := range def.Productions {
+			if production.Head.Value != head {
+				continue
+			}
+			for _, symbol := range production.Body {
+				if !symbol.IsTerminal && !reached[symbol.Value] {
+					reached[symbol.Value] = true
+					pending = append(pending, symbol.Value)
+				}
+			}
+		}
+	}
+
+	warnings := make([]Warning, 0)
+	for _, nonTerminal := range def.NonTerminals {
+		if !reached[nonTerminal.Value] {
+			warnings = append(warnings, Warning{
+				Kind:    UNREACHABLE,
+				Symbol:  nonTerminal.Value,
+				Pos:     declaredAt(def, nonTerminal.Value),
+				Message: fmt.Sprintf("%s is unreachable from %s", nonTerminal.Value, start),
+			})
+		}
+	}
+	return warnings
+}
+
+// Non terminals without a production made of terminals and productive non
+// terminals, every derivation of them goes on forever.
+func unproductive(def *parser.ParserDefinition) []Warning {
+	productive := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, production := range def.Productions {
+			if productive[production.Head.Value] {
+				continue
+			}
+			all := true
+			for _, symbol := range production.Body {
+				if !symbol.IsTerminal && !productive[symbol.Value] {
+					all = false
+					break
+				}
+			}
+			if all {
+				productive[production.Head.Value] = true
+				changed = true
+			}
+		}
+	}
+
+	warnings := make([]Warning, 0)
+	for _, nonTerminal := range def.NonTerminals {
+		if !productive[nonTerminal.Value] {
+			warnings = append(warnings, Warning{
+				Kind:    UNPRODUCTIVE,
+				Symbol:  nonTerminal.Value,
+				Pos:     declaredAt(def, nonTerminal.Value),
+				Message: fmt.Sprintf("%s is unproductive, none of its derivations ends in terminals only", nonTerminal.Value),
+			})
+		}
+	}
+	return warnings
+}
+
+// Tokens on the default channel no production uses. Tokens on other channels
+// never reach the parser, so they are left out.
+func unusedTokens(def *parser.ParserDefinition) []Warning {
+	used := make(map[string]bool)
+	for _, production := range def.Productions {
+		for _, symbol := range production.Body {
+			if symbol.IsTerminal {
+				used[symbol.Value] = true
+			}
+		}
+	}
+
+	warnings := make([]Warning, 0)
+	for _, terminal := range def.Terminals {
+		if !used[terminal.Value] {
+			warnings = append(warnings, Warning{
+				Kind:    UNUSED_TOKEN,
+				Symbol:  terminal.Value,
+				Pos:     def.TokenPositions[terminal.Id],
+				Message: fmt.Sprintf("token %s is declared but no production uses it", terminal.Value),
+			})
+		}
+	}
+	return warnings
+}
+
+// Productions with the same head and body as an earlier one.
+func duplicates(def *parser.ParserDefinition) []Warning {
+	first := make(map[string]parser.ParserProduction)
+	warnings := make([]Warning, 0)
+	for _, production := range def.Productions {
+		key := production.Head.Value + ":" + bodyKey(production.Body)
+		original, seen := first[key]
+		if !seen {
+			first[key] = production
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Kind:        DUPLICATE_PRODUCTION,
+			Symbol:      production.Head.Value,
+			Productions: []int{original.Id, production.Id},
+			Pos:         production.Pos,
+			Message:     fmt.Sprintf("production %s is the same as production %d", production.String(), original.Id),
+		})
+	}
+	return warnings
+}
+
+// Cycles of unit productions, whose body is a single non terminal: A → B, B → A.
+// The shortest cycle of each non terminal is reported, unless the non terminal
+// is on a cycle reported before. A cycle through nullable symbols, like A → B C
+// with C nullable, is not looked for.
+func unitCycles(def *parser.ParserDefinition) []Warning {
+	units := make(map[string][]parser.ParserProduction)
+	for _, production := range def.Productions {
+		if len(production.Body) == 1 && !production.Body[0].IsTerminal {
+			units[production.Head.Value] = append(units[production.Head.Value], production)
+		}
+	}
+
+	reported := make(map[string]bool)
+	warnings := make([]Warning, 0)
+	for _, nonTerminal := range def.NonTerminals {
+		if reported[nonTerminal.Value] {
+			continue
+		}
+		cycle := shortestCycle(nonTerminal.Value, units)
+		if cycle == nil {
+			continue
+		}
+
+		path := []string{nonTerminal.Value}
+		ids := make([]int, 0, len(cycle))
+		for _, production := range cycle {
+			reported[production.Head.Value] = true
+			path = append(path, production.Body[0].Value)
+			ids = append(ids, production.Id)
+		}
+		warnings = append(warnings, Warning{
+			Kind:        UNIT_CYCLE,
+			Symbol:      nonTerminal.Value,
+			Productions: ids,
+			Pos:         cycle[len(cycle)-1].Pos,
+			Message:     fmt.Sprintf("unit productions form a cycle %s, %s derives itself", strings.Join(path, " → "), nonTerminal.Value),
+		})
+	}
+	return warnings
+}
+
+// Fewest unit productions leading from a non terminal back to itself, nil when
+// there is no way back. Searched breadth first, remembering how each non
+// terminal was reached.
+func shortestCycle(start string, units map[string][]parser.ParserProduction) []parser.ParserProduction {
+	reachedBy := make(map[string]parser.ParserProduction)
+	pending := []string{start}
+	for len(pending) > 0 {
+		head := pending[0]
+		pending = pending[1:]
+		for _, production := range units[head] {
+			next := production.Body[0].Value
+			if next == start {
+				cycle := []parser.ParserProduction{production}
+				for at := head; at != start; at = reachedBy[at].Head.Value {
+					cycle = append([]parser.ParserProduction{reachedBy[at]}, cycle...)
+				}
+				return cycle
+			}
+			if _, seen := reachedBy[next]; !seen {
+				reachedBy[next] = production
+				pending = append(pending, next)
+			}
+		}
+	}
+	return nil
+}
+
+// Position of the first production of a non terminal.
+func declaredAt(def *parser.ParserDefinition, nonTerminal string) io.Position {
+	for _, production := range def.Productions {
+		if production.Head.Value == nonTerminal {
+			return production.Pos
+		}
+	}
+	return io.Position{}
+}
+
+func bodyKey(body []parser.ParserSymbol) string {
+	values := make([]string, 0, len(body))
+	for _, symbol := range body {
+		values = append(values, symbol.Value)
+	}
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+)
+
+const broken = `%token a b c UNUSED
+IGNORE WS
+%%
+s: x | y ;
+x: a | a | loop ;
+y: b y ;
+loop: other ;
+other: loop | s ;
+lost: c ;
+`
+
+func Test_analyze(t *testing.T) {
+	definition, err := reader.ParseSource(io.NewSource("broken.par", broken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := Analyze(definition)
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+
+	expected := []string{
+		"broken.par:9:1: warning: lost is unreachable from s",
+		"broken.par:6:1: warning: y is unproductive, none of its derivations ends in terminals only",
+		"broken.par:1:14: warning: token UNUSED is declared but no production uses it",
+		"broken.par:5:1: warning: production 4: x → a is the same as production 3",
+		"broken.par:8:1: warning: unit productions form a cycle s → x → loop → other → s, s derives itself",
+	}
+	got := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		got = append(got, w.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// loop → other → loop shares its non terminals with the cycle found first
+	cycle := warnings[len(warnings)-1]
+	if cycle.Kind != UNIT_CYCLE || fmt.Sprint(cycle.Productions) != "[1 5 7 9]" {
+		t.Errorf("unexpected cycle %+v", cycle)
+	}
+	encoded, err := json.Marshal(warnings[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"kind":"unused-token","symbol":"UNUSED","position":{"file":"broken.par","line":1,"column":14},"message":"token UNUSED is declared but no production uses it"}` {
+		t.Errorf("unexpected json %s", encoded)
+	}
+}
+
+func Test_examples(t *testing.T) {
+	for _, example := range []string{"superSimple", "simple", "medium", "ll1", "ambiguous"} {
+		definition, err := reader.Parse("../../../examples/" + example + ".par")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if warnings := Analyze(definition); len(warnings) > 0 {
+			t.Errorf("%s: unexpected warnings %v", example, warnings)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"fmt"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+)
+
+// Kind of problem found on a grammar.
+type Kind string
+
+const (
+	UNREACHABLE          Kind = "unreachable"          // Non terminal the start symbol never derives
+	UNPRODUCTIVE         Kind = "unproductive"         // Non terminal deriving no string of terminals
+	UNUSED_TOKEN         Kind = "unused-token"         // %token no production uses
+	DUPLICATE_PRODUCTION Kind = "duplicate-production" // Same head and body as an earlier production
+	UNIT_CYCLE           Kind = "unit-cycle"           // Productions A → B, B → A deriving a symbol from itself
+)
+
+// A problem of the grammar. It doesn't stop the parser from being generated,
+// but it usually is a mistake.
+//
+//	{Kind: "unreachable", Symbol: "block", Pos: "medium.par:30:1", Message: "block is unreachable from program"}
+type Warning struct {
+	Kind Kind `json:"kind"`
+	// Non terminal or token the warning is about, the head for productions
+	Symbol string `json:"symbol"`
+	// Ids of the productions involved: the duplicated ones, or the ones forming a cycle
+	Productions []int `json:"productions,omitempty"`
+	// Where the symbol was declared, or the last production involved
+	Pos     io.Position `json:"position"`
+	Message string      `json:"message"`
+}
+
+// Printed like the diagnostics of the reader:
+//
+//	medium.par:30:1: warning: block is unreachable from program
+func (w Warning) String() string {
+	if w.Pos.Line == 0 {
+		return fmt.Sprintf("warning: %s", w.Message)
+	}
+	return fmt.Sprintf("%s: warning: %s", w.Pos, w.Message)
+}
@@ -0,0 +1,221 @@
+package earley
+
+import (
+	"sort"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	glr "github.com/DanielRasho/Parser/internal/Parser/GLR"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+// NewParser builds an Earley parser for the grammar, its start symbol is the
+// head of the first production.
+func NewParser(definition *parser.ParserDefinition) *Parser {
+	start := definition.Productions[0].Head
+	p := &Parser{
+		definition: definition,
+		rules:      make([]rule, 0, len(definition.Productions)+1),
+		byHead:     make(map[string][]int),
+		nullable:   make(map[string]bool),
+	}
+	root := parser.ParserSymbol{Id: parser.NON_TERMINAL_ID, Value: start.Value + "'"}
+	p.rules = append(p.rules, rule{head: root, body: []parser.ParserSymbol{start}})
+	for _, production := range definition.Productions {
+		p.byHead[production.Head.Value] = append(p.byHead[production.Head.Value], len(p.rules))
+		p.rules = append(p.rules, rule{head: production.Head, body: production.Body})
+	}
+	for symbol, first := range table.GetFirst(definition) {
+		if _, ok := first[parser.EPSILON]; ok {
+			p.nullable[symbol] = true
+		}
+	}
+	return p
+}
+
+// Recognize tells whether the grammar derives the list of terminals, failing
+// with a *glr.SyntaxError at the first token no sentence can continue with.
+func (p *Parser) Recognize(tokens []string) error {
+	_, err := p.recognize(tokens)
+	return err
+}
+
+// Parse reads a list of terminals and returns the forest of all its parses,
+// the same one the GLR parser gives, so its Tree, Count and Disambiguate work
+// the same. Unlike the GLR parser, the grammar can have ε-productions.
+func (p *Parser) Parse(tokens []string) (*glr.Forest, error) {
+	c, err := p.recognize(tokens)
+	if err != nil {
+		return nil, err
+	}
+	b := &builder{
+		parser: p,
+		chart:  c,
+		nodes:  make(map[nodeKey]*glr.Node),
+		splits: make(map[splitKey][][]*glr.Node),
+	}
+	start := p.rules[0].body[0]
+	return &glr.Forest{Root: b.node(start, 0, len(tokens)), Tokens: tokens}, nil
+}
+
+// Builds a set for each position of the input. Items on a set are processed in
+// order, each one may add items to the same set or the next one:
+//   - Before a terminal matching the next token, it moves over it on the next set.
+//   - Before a non terminal, its rules are added from this position. When it is
+//     nullable the item also moves over it, so items predicted later don't need
+//     the empty completions done before them.
+//   - Completed, every item of its origin waiting for its head moves over it,
+//     or just the top of the chain when there is a Leo item.
+func (p *Parser) recognize(tokens []string) (*chart, error) {
+	c := &chart{leo: make(map[leoKey]*leoItem), tokens: tokens}
+	c.sets = append(c.sets, newSet())
+	c.add(0, item{rule: 0, dot: 0, origin: 0})
+
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) {
+			c.sets = append(c.sets, newSet())
+		}
+		set := c.sets[i]
+		for k := 0; k < len(set.items); k++ {
+			current := set.items[k]
+			body := p.rules[current.rule].body
+			if current.dot == len(body) {
+				p.complete(c, i, current)
+				continue
+			}
+			next := body[current.dot]
+			if next.IsTerminal {
+				if i < len(tokens) && tokens[i] == next.Value {
+					c.add(i+1, advance(current))
+				}
+				continue
+			}
+			for _, r := range p.byHead[next.Value] {
+				c.add(i, item{rule: r, dot: 0, origin: i})
+			}
+			if p.nullable[next.Value] {
+				c.add(i, advance(current))
+			}
+		}
+
+		if i < len(tokens) && len(c.sets[i+1].items) == 0 {
+			return nil, &glr.SyntaxError{Position: i, Token: tokens[i], Expected: p.expected(set)}
+		}
+	}
+
+	last := c.sets[len(tokens)]
+	if !last.has[item{rule: 0, dot: 1, origin: 0}] {
+		return nil, &glr.SyntaxError{Position: len(tokens), Token: "$", Expected: p.expected(last)}
+	}
+	return c, nil
+}
+
+func (p *Parser) complete(c *chart, position int, completed item) {
+	head := p.rules[completed.rule].head.Value
+	set := c.sets[position]
+	key := completedKey{symbol: head, origin: completed.origin}
+	set.completed[key] = append(set.completed[key], completed.rule)
+
+	// Empty completions are left to the nullable check of the prediction, the set
+	// of its origin is still growing so it can't have a Leo item yet.
+	if completed.origin < position {
+		if leo := p.leoItem(c, completed.origin, head); leo.ok {
+			set.leoEvents = append(set.leoEvents, key)
+			c.add(position, leo.top)
+			return
+		}
+	}
+	origin := c.sets[completed.origin]
+	for k := 0; k < len(origin.items); k++ {
+		if waiting := origin.items[k]; p.postdot(waiting) == head {
+			c.add(position, advance(waiting))
+		}
+	}
+}
+
+// Finds the Leo item of a non terminal on a finished set, it is kept for later
+// completions. Leo items going around a cycle of unit productions are not used.
+func (p *Parser) leoItem(c *chart, position int, symbol string) *leoItem {
+	key := leoKey{set: position, symbol: symbol}
+	if leo, ok := c.leo[key]; ok {
+		return leo
+	}
+	leo := &leoItem{}
+	c.leo[key] = leo
+
+	waiting := make([]item, 0, 1)
+	for _, candidate := range c.sets[position].items {
+		if p.postdot(candidate) == symbol {
+			waiting = append(waiting, candidate)
+		}
+	}
+	if len(waiting) != 1 || waiting[0].dot != len(p.rules[waiting[0].rule].body)-1 {
+		return leo
+	}
+	leo.via = waiting[0]
+	leo.top = advance(leo.via)
+	if above := p.leoItem(c, leo.via.origin, p.rules[leo.via.rule].head.Value); above.ok {
+		leo.top = above.top
+	}
+	leo.ok = true
+	return leo
+}
+
+// Non terminal after the dot of an item, empty when there is none.
+func (p *Parser) postdot(it item) string {
+	body := p.rules[it.rule].body
+	if it.dot == len(body) || body[it.dot].IsTerminal {
+		return ""
+	}
+	return body[it.dot].Value
+}
+
+// Terminals some item of the set is waiting for, sorted. With the end of input
+// when the tokens before the set are a sentence.
+func (p *Parser) expected(set *earleySet) []string {
+	unique := make(map[string]struct{})
+	if set.has[item{rule: 0, dot: 1, origin: 0}] {
+		unique["$"] = struct{}{}
+	}
+	for _, it := range set.items {
+		body := p.rules[it.rule].body
+		if it.dot < len(body) && body[it.dot].IsTerminal {
+			unique[body[it.dot].Value] = struct{}{}
+		}
+	}
+	expected := make([]string, 0, len(unique))
+	for symbol := range unique {
+		expected = append(expected, symbol)
+	}
+	sort.Strings(expected)
+	return expected
+}
+
+func newSet() *earleySet {
+	return &earleySet{
+		items:     make([]item, 0),
+		has:       make(map[item]bool),
+		completed: make(map[completedKey][]int),
+	}
+}
+
+func (c *chart) add(position int, it item) {
+	set := c.sets[position]
+	if set.has[it] {
+		return
+	}
+	set.has[it] = true
+	set.items = append(set.items, it)
+}
+
+// Number of items on every set.
+func (c *chart) size() int {
+	total := 0
+	for _, set := range c.sets {
+		total += len(set.items)
+	}
+	return total
+}
+
+func advance(it item) item {
+	return item{rule: it.rule, dot: it.dot + 1, origin: it.origin}
+}
@@ -0,0 +1,282 @@
+package earley
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	fuzzer "github.com/DanielRasho/Parser/internal/Parser/Fuzzer"
+	glr "github.com/DanielRasho/Parser/internal/Parser/GLR"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+	"github.com/DanielRasho/Parser/internal/Parser/automata"
+)
+
+func parse(t *testing.T, content string) *parser.ParserDefinition {
+	definition, err := reader.ParseSource(io.NewSource("earley.par", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return definition
+}
+
+func Test_ambiguous(t *testing.T) {
+	p := NewParser(parse(t, "%token int + * ^\n%%\nE: E + E | E * E | E ^ E | int ;"))
+
+	// The number of ways to group n operands
+	counts := []struct {
+		input string
+		trees int
+	}{
+		{"int", 1},
+		{"int + int", 1},
+		{"int + int * int", 2},
+		{"int + int + int + int", 5},
+		{"int * int + int ^ int + int", 14},
+	}
+	for _, c := range counts {
+		forest, err := p.Parse(strings.Fields(c.input))
+		if err != nil {
+			t.Fatalf("%s: %s", c.input, err)
+		}
+		if forest.Count() != c.trees {
+			t.Errorf("%s: expected %d trees, got %d", c.input, c.trees, forest.Count())
+		}
+	}
+
+	forest, _ := p.Parse(strings.Fields("int + int * int"))
+	var ambiguity *glr.AmbiguityError
+	if _, err := forest.Tree(); !errors.As(err, &ambiguity) || ambiguity.Node.Start != 0 || ambiguity.Node.End != 5 {
+		t.Errorf("expected the whole input to be ambiguous, got %v", err)
+	}
+	forest.Disambiguate(glr.Priority(1, 0))
+	if tree, err := forest.Tree(); err != nil || tree.String() != "E(E(int) + E(E(int) * E(int)))" {
+		t.Errorf("unexpected tree %v, %v", tree, err)
+	}
+}
+
+func Test_empty(t *testing.T) {
+	cases := []struct {
+		grammar  string
+		input    string
+		expected string
+	}{
+		{"%token a b\n%%\nS: A S b | %empty ;\nA: a | %empty ;", "b b", "S(A() S(A() S() b) b)"},
+		{"%token a\n%%\nS: A A a ;\nA: %empty ;", "a", "S(A() A() a)"},
+		{"%token a\n%%\nS: A ;\nA: B ;\nB: %empty | a ;", "", "S(A(B()))"},
+	}
+	for _, c := range cases {
+		forest, err := NewParser(parse(t, c.grammar)).Parse(strings.Fields(c.input))
+		if err != nil {
+			t.Errorf("%q: %s", c.input, err)
+			continue
+		}
+		tree, err := forest.Tree()
+		if err != nil {
+			t.Errorf("%q: %s", c.input, err)
+			continue
+		}
+		if tree.String() != c.expected {
+			t.Errorf("%q: expected %s, got %s", c.input, c.expected, tree)
+		}
+	}
+
+	// The a goes to either A, and the empty string is derived two ways
+	ambiguous := []struct {
+		grammar string
+		input   string
+		trees   int
+	}{
+		{"%token a b\n%%\nS: A S b | %empty ;\nA: a | %empty ;", "a b b", 2},
+		{"%token a\n%%\nS: A | B ;\nA: %empty ;\nB: %empty ;", "", 2},
+	}
+	for _, c := range ambiguous {
+		forest, err := NewParser(parse(t, c.grammar)).Parse(strings.Fields(c.input))
+		if err != nil || forest.Count() != c.trees {
+			t.Errorf("%q: expected %d trees, got %v", c.input, c.trees, err)
+		}
+	}
+}
+
+func Test_syntaxError(t *testing.T) {
+	p := NewParser(parse(t, "%token int + * ^\n%%\nE: E + E | E * E | E ^ E | int ;"))
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"int +", `unexpected end of input, expected one of: int`},
+		{"int int", `unexpected "int" at token 1, expected one of: $ * + ^`},
+		{"", `unexpected end of input, expected one of: int`},
+	}
+	for _, c := range cases {
+		err := p.Recognize(strings.Fields(c.input))
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("%q: expected %q, got %v", c.input, c.expected, err)
+		}
+	}
+}
+
+// Right recursion completes a chain as long as the input on every set, the Leo
+// items keep a constant number of items per set.
+func Test_rightRecursion(t *testing.T) {
+	p := NewParser(parse(t, "%token x sep\n%%\nL: x sep L | x ;"))
+	tokens := make([]string, 0)
+	for i := 0; i < 1000; i++ {
+		tokens = append(tokens, "x", "sep")
+	}
+	tokens = tokens[:len(tokens)-1]
+
+	c, err := p.recognize(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%d tokens, %d items\n", len(tokens), c.size())
+	if c.size() > 5*len(tokens) {
+		t.Errorf("expected a linear number of items, got %d for %d tokens", c.size(), len(tokens))
+	}
+
+	forest, err := p.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := forest.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaves := strings.Join(leaves(tree), " "); leaves != strings.Join(tokens, " ") {
+		t.Errorf("expected the input back, got %q", leaves)
+	}
+}
+
+func Test_cycle(t *testing.T) {
+	forest, err := NewParser(parse(t, "%token x\n%%\nS: A ;\nA: B | x ;\nB: A ;")).Parse([]string{"x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forest.Count() != 1 {
+		t.Errorf("expected 1 tree, got %d", forest.Count())
+	}
+	forest.Disambiguate(glr.Prefer(2))
+	if tree, err := forest.Tree(); err != nil || tree.String() != "S(A(x))" {
+		t.Errorf("unexpected tree %v, %v", tree, err)
+	}
+}
+
+// Earley must accept and reject the same inputs as the LR tables, on the
+// sentences of each example and on broken copies of them, giving the same tree.
+func Test_oracle(t *testing.T) {
+	for _, example := range []string{"superSimple", "simple", "medium", "hard", "hard2", "ambiguous"} {
+		definition, err := reader.Parse("../../../examples/" + example + ".par")
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := table.GetFirst(definition)
+		follow := table.GetFollow(definition, first)
+		transit, gotos, err := table.NewTable(automata.NewAutomata(definition, false), first, follow, *definition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lr := glr.NewParser(definition, *transit, *gotos)
+		p := NewParser(definition)
+		f, err := fuzzer.NewFuzzer(definition)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		random := rand.New(rand.NewSource(49))
+		accepted, rejected := 0, 0
+		for i := 0; i < 200; i++ {
+			sentence := strings.Fields(f.Generate(fuzzer.Options{MaxDepth: 6, Random: random}).Text(" "))
+			if i%2 == 1 {
+				sentence = mutate(sentence, definition, random)
+			}
+			expected, lrErr := lr.Parse(sentence)
+			got, err := p.Parse(sentence)
+			if (lrErr == nil) != (err == nil) {
+				t.Errorf("%s: %q: LR says %v, Earley says %v", example, strings.Join(sentence, " "), lrErr, err)
+				continue
+			}
+			if err != nil {
+				rejected++
+				// Both stop on the same token, SLR expects more terminals by reducing on FOLLOW
+				var lrSyntax, syntax *glr.SyntaxError
+				if !errors.As(lrErr, &lrSyntax) || !errors.As(err, &syntax) || lrSyntax.Position != syntax.Position {
+					t.Errorf("%s: %q: LR says %v, Earley says %v", example, strings.Join(sentence, " "), lrErr, err)
+				}
+				continue
+			}
+			accepted++
+			if expected.Count() != got.Count() {
+				t.Errorf("%s: %q: LR found %d trees, Earley %d", example, strings.Join(sentence, " "), expected.Count(), got.Count())
+				continue
+			}
+			if expectedTree, err := expected.Tree(); err == nil {
+				if tree, _ := got.Tree(); tree.String() != expectedTree.String() {
+					t.Errorf("%s: expected %s, got %s", example, expectedTree, tree)
+				}
+			}
+		}
+		fmt.Printf("%s: %d accepted, %d rejected\n", example, accepted, rejected)
+	}
+}
+
+// Sentences of a grammar with ε-productions give back their tokens.
+func Test_ll1(t *testing.T) {
+	definition, err := reader.Parse("../../../examples/ll1.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser(definition)
+	f, err := fuzzer.NewFuzzer(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewSource(49))
+	for i := 0; i < 100; i++ {
+		sentence := strings.Fields(f.Generate(fuzzer.Options{MaxDepth: 7, Random: random}).Text(" "))
+		forest, err := p.Parse(sentence)
+		if err != nil {
+			t.Fatalf("%q: %s", strings.Join(sentence, " "), err)
+		}
+		tree, err := forest.Tree()
+		if err != nil {
+			t.Fatalf("%q: %s", strings.Join(sentence, " "), err)
+		}
+		if leaves := strings.Join(leaves(tree), " "); leaves != strings.Join(sentence, " ") {
+			t.Fatalf("expected %q, got %q", strings.Join(sentence, " "), leaves)
+		}
+	}
+}
+
+// Drops, repeats or replaces a token.
+func mutate(sentence []string, definition *parser.ParserDefinition, random *rand.Rand) []string {
+	mutated := append([]string{}, sentence...)
+	i := random.Intn(len(mutated) + 1)
+	terminal := definition.Terminals[random.Intn(len(definition.Terminals))].Value
+	switch {
+	case i == len(mutated):
+		mutated = append(mutated, terminal)
+	case random.Intn(3) == 0:
+		mutated = append(mutated[:i], mutated[i+1:]...)
+	case random.Intn(2) == 0:
+		mutated = append(mutated[:i+1], mutated[i:]...)
+	default:
+		mutated[i] = terminal
+	}
+	return mutated
+}
+
+func leaves(tree *glr.Tree) []string {
+	if tree.Production < 0 {
+		return []string{tree.Symbol.Value}
+	}
+	values := make([]string, 0)
+	for _, child := range tree.Children {
+		values = append(values, leaves(child)...)
+	}
+	return values
+}
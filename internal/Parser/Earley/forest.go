@@ -0,0 +1,108 @@
+package earley
+
+import (
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	glr "github.com/DanielRasho/Parser/internal/Parser/GLR"
+)
+
+// Returns the node of a symbol deriving the tokens between two positions,
+// building its alternatives the first time. The node is stored before them,
+// so a cycle of unit productions points back to it.
+func (b *builder) node(symbol parser.ParserSymbol, start, end int) *glr.Node {
+	key := nodeKey{symbol: symbol.Value, start: start, end: end}
+	if node, ok := b.nodes[key]; ok {
+		return node
+	}
+	node := &glr.Node{Symbol: symbol, Start: start, End: end}
+	b.nodes[key] = node
+	if symbol.IsTerminal {
+		return node
+	}
+	for _, r := range b.chart.completedRules(b.parser, end, symbol.Value, start) {
+		completed := item{rule: r, dot: len(b.parser.rules[r].body), origin: start}
+		for _, children := range b.split(completed, end) {
+			node.Packed = append(node.Packed, &glr.Packed{Production: r - 1, Children: children})
+		}
+	}
+	return node
+}
+
+// Every way the symbols before the dot of an item derive the tokens from its
+// origin to a position. The item, with the dot moved back over the last one,
+// must be on the set where that symbol starts.
+func (b *builder) split(it item, end int) [][]*glr.Node {
+	key := splitKey{item: it, end: end}
+	if splits, ok := b.splits[key]; ok {
+		return splits
+	}
+
+	splits := make([][]*glr.Node, 0)
+	if it.dot == 0 {
+		if it.origin == end {
+			splits = append(splits, []*glr.Node{})
+		}
+		b.splits[key] = splits
+		return splits
+	}
+
+	symbol := b.parser.rules[it.rule].body[it.dot-1]
+	before := item{rule: it.rule, dot: it.dot - 1, origin: it.origin}
+	first, last := it.origin, end
+	if symbol.IsTerminal {
+		// A terminal covers the token before the position
+		first, last = end-1, end-1
+	}
+	for k := first; k <= last; k++ {
+		if k < it.origin || !b.chart.sets[k].has[before] {
+			continue
+		}
+		if symbol.IsTerminal && b.chart.tokens[k] != symbol.Value {
+			continue
+		}
+		if !symbol.IsTerminal && len(b.chart.completedRules(b.parser, end, symbol.Value, k)) == 0 {
+			continue
+		}
+		child := b.node(symbol, k, end)
+		for _, prefix := range b.split(before, k) {
+			children := append(append(make([]*glr.Node, 0, it.dot), prefix...), child)
+			splits = append(splits, children)
+		}
+	}
+	b.splits[key] = splits
+	return splits
+}
+
+// Rules of a non terminal completed on a set from an origin. The first time a
+// set is asked, the completions its Leo items skipped are added: walking down
+// from each one, every item waiting on the chain completes too.
+func (c *chart) completedRules(p *Parser, position int, symbol string, origin int) []int {
+	set := c.sets[position]
+	if !set.expanded {
+		set.expanded = true
+		for _, event := range set.leoEvents {
+			leo := c.leo[leoKey{set: event.origin, symbol: event.symbol}]
+			for leo != nil && leo.ok {
+				completed := advance(leo.via)
+				head := p.rules[completed.rule].head.Value
+				key := completedKey{symbol: head, origin: completed.origin}
+				if !containsRule(set.completed[key], completed.rule) {
+					set.completed[key] = append(set.completed[key], completed.rule)
+				}
+				if completed == leo.top {
+					break
+				}
+				leo = c.leo[leoKey{set: completed.origin, symbol: head}]
+			}
+		}
+	}
+	return set.completed[completedKey{symbol: symbol, origin: origin}]
+}
+
+func containsRule(rules []int, r int) bool {
+	for _, candidate := range rules {
+		if candidate == r {
+			return true
+		}
+	}
+	return false
+}
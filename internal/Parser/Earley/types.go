@@ -0,0 +1,89 @@
+package earley
+
+import (
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	glr "github.com/DanielRasho/Parser/internal/Parser/GLR"
+)
+
+// Parser recognizes the sentences of any context free grammar, ambiguous,
+// left or right recursive, or with ε-productions. It needs no tables, so it
+// works on grammars the LR and LL(1) modes reject.
+type Parser struct {
+	definition *parser.ParserDefinition
+	// rules[0] is the root S' → S, rules[i] the production i-1 of the definition
+	rules    []rule
+	byHead   map[string][]int // Rules of each non terminal
+	nullable map[string]bool  // Non terminals deriving the empty string
+}
+
+type rule struct {
+	head parser.ParserSymbol
+	body []parser.ParserSymbol
+}
+
+// A rule with a dot on its body, from the position of the input it started at.
+//
+//	E → E • + T, 0
+type item struct {
+	rule   int
+	dot    int
+	origin int
+}
+
+// Items reached after reading the tokens before a position.
+type earleySet struct {
+	items []item
+	has   map[item]bool
+	// Rules completed on this set by non terminal and origin, for building the
+	// forest. The ones a Leo item skipped are added once the forest needs them.
+	completed map[completedKey][]int
+	leoEvents []completedKey // Completions done with a Leo item
+	expanded  bool
+}
+
+type completedKey struct {
+	symbol string
+	origin int
+}
+
+// Leo item of a set and non terminal: when a set has a single item waiting for
+// the non terminal, and it is the last symbol of its rule, completing it leads
+// to a chain of completions with one item each (like on right recursion). Only
+// the top of the chain is added, instead of every item on it.
+type leoItem struct {
+	ok  bool
+	via item // The single item waiting for the non terminal
+	top item // Completed item at the top of the chain
+}
+
+type leoKey struct {
+	set    int
+	symbol string
+}
+
+// The sets built while reading an input.
+type chart struct {
+	sets   []*earleySet
+	leo    map[leoKey]*leoItem
+	tokens []string
+}
+
+// Builds the forest out of a chart, nodes are shared like on glr.Forest.
+type builder struct {
+	parser *Parser
+	chart  *chart
+	nodes  map[nodeKey]*glr.Node
+	splits map[splitKey][][]*glr.Node
+}
+
+// Children of the symbols before the dot of an item, ending at a position.
+type splitKey struct {
+	item item
+	end  int
+}
+
+type nodeKey struct {
+	symbol string
+	start  int
+	end    int
+}
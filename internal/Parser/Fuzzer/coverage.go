@@ -0,0 +1,640 @@
+package fuzzer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+// How a lookahead ends when fed to the parser
+const (
+	shifted = iota
+	accepted
+	rejected
+)
+
+// Runs the transition and goto tables the same way the generated parser does
+type machine struct {
+	transit     table.DeterministicTbl
+	gotos       table.GotoTbl
+	productions []parser.ParserProduction
+	lookaheads  []string // Terminals the parser reads, $ last
+
+	// Symbol of the transitions going into each state and the states they come from
+	symbols      map[string]string
+	predecessors map[string][]string
+	// Stack with the shortest input that takes the parser to each state
+	paths    map[string][]string
+	shortest map[string][]string // Shortest sentence of each non terminal
+	// Items of each state past their first symbol
+	items map[string][]item
+}
+
+// A production with a dot after its first dot symbols
+type item struct {
+	production int
+	dot        int
+}
+
+// A stack of the parser while it reduces before a lookahead. Only its top
+// states are known: any path of the automata from the first state is a stack
+// some input takes the parser to, so below them any path will do.
+type frame struct {
+	start    []string // Stack once the last terminal was shifted
+	junction int      // start[junction] is the deepest known state, the stack is start[:junction+1]
+	pushed   string   // State on top of it after a goto, "" before any reduction
+}
+
+// How a lookahead ends being fed to a frame
+type outcome struct {
+	ok bool
+	// Path to the known state of the frame that gets the lookahead shifted
+	below []string
+	// Stack once the lookahead was shifted, nil when accepted
+	stack []string
+}
+
+// An input going through a cell and the stack it leaves
+type witness struct {
+	tokens []string
+	stack  []string
+}
+
+// Cover builds a small set of inputs that together go through every filled cell
+// of the tables, plus a rejected input for each empty cell of the transition
+// table the parser can stop on. Inputs are lists of terminals.
+//
+// Every lookahead is fed to every state reached by a terminal, branching over
+// all the stacks reductions can uncover. Each cell found is then finished into
+// an accepted input, and those inputs are reduced to the ones covering
+// something the others don't. The first movement of a cell is the only one
+// followed, so the table should have no conflicts.
+func Cover(definition *parser.ParserDefinition, transit *table.TransitionTbl, gotos *table.GotoTbl) *Coverage {
+	m := newMachine(definition, table.Deterministic(*transit), *gotos)
+
+	witnesses := make(map[Cell]witness)   // First accepted input through each filled cell
+	rejections := make(map[Cell][]string) // First input stopping on each empty cell
+	cells := make([]Cell, 0)              // Filled cells in the order they were found
+	errors := make([]Cell, 0)
+
+	starts := []string{"0"}
+	for state := range m.paths {
+		if table.CheckTerminal(m.symbols[state], *definition) {
+			starts = append(starts, state)
+		}
+	}
+	sortStates(starts)
+
+	for _, lookahead := range m.lookaheads {
+		// Frames only depend on their top states, so the outcome of each one
+		// is kept.
+		results := make(map[string]outcome)
+		found := func(c Cell, f frame, result outcome) {
+			if _, exist := witnesses[c]; !exist {
+				start := append(append([]string{}, result.below...), f.start[f.junction+1:]...)
+				witnesses[c] = witness{tokens: m.input(start, lookahead), stack: result.stack}
+				cells = append(cells, c)
+			}
+		}
+
+		var feed func(f frame) outcome
+		feed = func(f frame) outcome {
+			top := f.start[f.junction]
+			if f.pushed != "" {
+				top = f.pushed
+			}
+			key := f.start[f.junction] + " " + f.pushed
+			if result, exist := results[key]; exist {
+				return result
+			}
+			results[key] = outcome{} // Cycles of reductions are rejections
+
+			result := outcome{}
+			move, ok := m.transit[top][lookahead]
+			switch {
+			case !ok:
+				c := Cell{top, lookahead}
+				if _, exist := rejections[c]; !exist {
+					rejections[c] = m.input(f.start, lookahead)
+					errors = append(errors, c)
+				}
+			case move.MovementType == table.SHIFT:
+				stack := append([]string{}, f.start[:f.junction+1]...)
+				if f.pushed != "" {
+					stack = append(stack, f.pushed)
+				}
+				stack = append(stack, strconv.Itoa(move.NextRow))
+				result = outcome{ok: true, below: f.start[:f.junction+1], stack: stack}
+			case move.MovementType == table.ACCEPT:
+				result = outcome{ok: true, below: f.start[:f.junction+1]}
+			case move.MovementType == table.REDUCE:
+				production := m.productions[move.NextRow]
+				above := len(f.start) - f.junction - 1
+				for _, child := range m.reduce(f, production) {
+					r := feed(child)
+					if !r.ok {
+						continue
+					}
+					found(Cell{child.start[child.junction], production.Head.Value}, child, r)
+					if !result.ok {
+						// The states popped by the reduction go back on top of the path
+						popped := child.start[child.junction+1 : len(child.start)-above]
+						result = outcome{ok: true, below: append(append([]string{}, r.below...), popped...), stack: r.stack}
+					}
+				}
+			}
+
+			if result.ok {
+				found(Cell{top, lookahead}, f, result)
+			}
+			results[key] = result
+			return result
+		}
+
+		for _, state := range starts {
+			feed(frame{start: m.paths[state], junction: len(m.paths[state]) - 1})
+		}
+	}
+
+	// Finish the input of each cell and keep the ones covering new cells, the
+	// ones covering the most go first.
+	candidates := make([]Case, 0, len(cells))
+	finished := make(map[string]struct{})
+	for _, c := range cells {
+		w := witnesses[c]
+		tokens := w.tokens
+		if w.stack != nil {
+			suffix, ok := m.complete(w.stack)
+			if !ok {
+				continue
+			}
+			tokens = append(append([]string{}, tokens...), suffix...)
+		}
+		key := strings.Join(tokens, " ")
+		if _, exist := finished[key]; exist {
+			continue
+		}
+		finished[key] = struct{}{}
+
+		if input, ok := m.run(tokens); ok {
+			candidates = append(candidates, input)
+		}
+	}
+
+	coverage := &Coverage{}
+	covered := make(map[Cell]struct{})
+	pending := make([][]Cell, len(candidates)) // Cells of each input not covered yet
+	for i := range candidates {
+		pending[i] = unique(candidates[i].Cells)
+	}
+	for {
+		best := -1
+		for i := range candidates {
+			kept := pending[i][:0]
+			for _, c := range pending[i] {
+				if _, exist := covered[c]; !exist {
+					kept = append(kept, c)
+				}
+			}
+			pending[i] = kept
+			if len(kept) == 0 {
+				continue
+			}
+			if best == -1 || len(kept) > len(pending[best]) ||
+				(len(kept) == len(pending[best]) && len(candidates[i].Tokens) < len(candidates[best].Tokens)) {
+				best = i
+			}
+		}
+		if best == -1 {
+			break
+		}
+		for _, c := range candidates[best].Cells {
+			covered[c] = struct{}{}
+		}
+		coverage.Valid = append(coverage.Valid, candidates[best])
+	}
+
+	sortCells(errors)
+	for _, c := range errors {
+		if input, ok := m.run(rejections[c]); !ok && input.Error != nil && *input.Error == c {
+			coverage.Invalid = append(coverage.Invalid, input)
+		}
+	}
+
+	for state, row := range m.transit {
+		for symbol := range row {
+			if _, exist := covered[Cell{state, symbol}]; !exist {
+				coverage.Missing = append(coverage.Missing, Cell{state, symbol})
+			}
+		}
+	}
+	for state, row := range m.gotos {
+		for symbol := range row {
+			if _, exist := covered[Cell{state, symbol}]; !exist {
+				coverage.Missing = append(coverage.Missing, Cell{state, symbol})
+			}
+		}
+	}
+	sortCells(coverage.Missing)
+
+	return coverage
+}
+
+func newMachine(definition *parser.ParserDefinition, transit table.DeterministicTbl, gotos table.GotoTbl) *machine {
+	m := &machine{
+		transit:      transit,
+		gotos:        gotos,
+		productions:  definition.Productions,
+		symbols:      make(map[string]string),
+		predecessors: make(map[string][]string),
+		paths:        map[string][]string{"0": {"0"}},
+		shortest:     shortestYields(definition),
+		items:        make(map[string][]item),
+	}
+
+	for _, terminal := range definition.Terminals {
+		if _, ignored := definition.IgnoredSymbol[terminal.Id]; !ignored {
+			m.lookaheads = append(m.lookaheads, terminal.Value)
+		}
+	}
+	m.lookaheads = append(m.lookaheads, "$")
+
+	states := make([]string, 0, len(transit))
+	for state := range transit {
+		states = append(states, state)
+	}
+	sortStates(states)
+	edges := func(state string, visit func(symbol, next string)) {
+		for _, terminal := range m.lookaheads {
+			if move, ok := transit[state][terminal]; ok && move.MovementType == table.SHIFT {
+				visit(terminal, strconv.Itoa(move.NextRow))
+			}
+		}
+		for _, nonTerminal := range definition.NonTerminals {
+			if move, ok := gotos[state][nonTerminal.Value]; ok {
+				visit(nonTerminal.Value, strconv.Itoa(move.NextRow))
+			}
+		}
+	}
+	for _, state := range states {
+		edges(state, func(symbol, next string) {
+			m.symbols[next] = symbol
+			m.predecessors[next] = append(m.predecessors[next], state)
+		})
+	}
+
+	// A state with a goto on a non terminal starts all of its productions,
+	// their items are on the states the body leads to.
+	for _, state := range states {
+		for i, production := range definition.Productions {
+			if _, ok := gotos[state][production.Head.Value]; !ok {
+				continue
+			}
+			current := state
+			for dot, symbol := range production.Body {
+				move, ok := transit[current][symbol.Value]
+				if !symbol.IsTerminal {
+					move, ok = gotos[current][symbol.Value]
+				}
+				if !ok || (symbol.IsTerminal && move.MovementType != table.SHIFT) {
+					break
+				}
+				current = strconv.Itoa(move.NextRow)
+				m.items[current] = append(m.items[current], item{production: i, dot: dot + 1})
+			}
+		}
+	}
+
+	// A terminal weights 1 and a non terminal as much as its shortest sentence.
+	// Weights are small so states are kept on a bucket for each distance.
+	distance := map[string]int{"0": 0}
+	buckets := [][]string{{"0"}}
+	for d := 0; d < len(buckets); d++ {
+		for _, state := range buckets[d] {
+			if distance[state] != d {
+				continue
+			}
+			edges(state, func(symbol, next string) {
+				weight := 1
+				if yield, ok := m.shortest[symbol]; ok {
+					weight = len(yield)
+				} else if !table.CheckTerminal(symbol, *definition) {
+					return
+				}
+				if current, exist := distance[next]; exist && current <= d+weight {
+					return
+				}
+				distance[next] = d + weight
+				m.paths[next] = append(append([]string{}, m.paths[state]...), next)
+				for len(buckets) <= d+weight {
+					buckets = append(buckets, nil)
+				}
+				buckets[d+weight] = append(buckets[d+weight], next)
+			})
+		}
+	}
+
+	return m
+}
+
+// Stacks left after reducing a production on a frame, one for each state the
+// reduction can uncover.
+func (m *machine) reduce(f frame, production parser.ParserProduction) []frame {
+	size := len(production.Body)
+	if f.pushed != "" {
+		size--
+	}
+
+	if size == 0 {
+		jump, ok := m.gotos[f.start[f.junction]][production.Head.Value]
+		if !ok {
+			return nil
+		}
+		return []frame{{start: f.start, junction: f.junction, pushed: strconv.Itoa(jump.NextRow)}}
+	}
+
+	// The states popped go below the known one, so any state with a path
+	// spelling the start of the body up to it can be uncovered.
+	body := production.Body[:size]
+	frames := make([]frame, 0)
+	for _, state := range m.walkBack(f.start[f.junction], body) {
+		jump, ok := m.gotos[state][production.Head.Value]
+		if !ok {
+			continue
+		}
+		start := append([]string{}, m.paths[state]...)
+		for _, symbol := range body {
+			move := m.transit[start[len(start)-1]][symbol.Value]
+			if !symbol.IsTerminal {
+				move = m.gotos[start[len(start)-1]][symbol.Value]
+			}
+			start = append(start, strconv.Itoa(move.NextRow))
+		}
+		start = append(start, f.start[f.junction+1:]...)
+		frames = append(frames, frame{start: start, junction: len(m.paths[state]) - 1, pushed: strconv.Itoa(jump.NextRow)})
+	}
+	return frames
+}
+
+// Reachable states from where the symbols lead to a state
+func (m *machine) walkBack(state string, symbols []parser.ParserSymbol) []string {
+	current := map[string]struct{}{state: {}}
+	for i := len(symbols) - 1; i >= 0; i-- {
+		previous := make(map[string]struct{})
+		for s := range current {
+			if m.symbols[s] != symbols[i].Value {
+				continue
+			}
+			for _, p := range m.predecessors[s] {
+				previous[p] = struct{}{}
+			}
+		}
+		current = previous
+	}
+
+	states := make([]string, 0, len(current))
+	for s := range current {
+		if _, reachable := m.paths[s]; reachable {
+			states = append(states, s)
+		}
+	}
+	sortStates(states)
+	return states
+}
+
+// Input that leaves a stack on the parser followed by a lookahead, non
+// terminals are written as their shortest sentence.
+func (m *machine) input(stack []string, lookahead string) []string {
+	tokens := make([]string, 0, len(stack))
+	for _, state := range stack[1:] {
+		symbol := m.symbols[state]
+		if yield, ok := m.shortest[symbol]; ok {
+			tokens = append(tokens, yield...)
+		} else {
+			tokens = append(tokens, symbol)
+		}
+	}
+	if lookahead != "$" {
+		tokens = append(tokens, lookahead)
+	}
+	return tokens
+}
+
+// Feeds a lookahead to the parser, reducing until it gets shifted, accepted or
+// rejected. Every cell read is passed to visit, the stack given isn't modified.
+func (m *machine) step(stack []string, lookahead string, visit func(Cell)) ([]string, int) {
+	stack = append([]string{}, stack...)
+
+	// Reductions never grow the stack, unless the grammar has cycles like A → B
+	// and B → A. Those are cut and taken as rejections.
+	for reductions := 0; reductions <= len(m.productions)*(len(stack)+1); reductions++ {
+		state := stack[len(stack)-1]
+		visit(Cell{state, lookahead})
+
+		move, ok := m.transit[state][lookahead]
+		if !ok {
+			return stack, rejected
+		}
+
+		switch move.MovementType {
+		case table.SHIFT:
+			return append(stack, strconv.Itoa(move.NextRow)), shifted
+		case table.ACCEPT:
+			return stack, accepted
+		case table.REDUCE:
+			production := m.productions[move.NextRow]
+			if len(production.Body) >= len(stack) {
+				return stack, rejected
+			}
+			stack = stack[:len(stack)-len(production.Body)]
+			state = stack[len(stack)-1]
+			visit(Cell{state, production.Head.Value})
+
+			jump, ok := m.gotos[state][production.Head.Value]
+			if !ok {
+				return stack, rejected
+			}
+			stack = append(stack, strconv.Itoa(jump.NextRow))
+		default:
+			return stack, rejected
+		}
+	}
+	return stack, rejected
+}
+
+// Parses a whole input, telling whether it was accepted
+func (m *machine) run(tokens []string) (Case, bool) {
+	input := Case{Tokens: tokens}
+	visit := func(c Cell) { input.Cells = append(input.Cells, c) }
+
+	stack := []string{"0"}
+	for i := 0; i <= len(tokens); i++ {
+		lookahead := "$"
+		if i < len(tokens) {
+			lookahead = tokens[i]
+		}
+
+		var outcome int
+		stack, outcome = m.step(stack, lookahead, visit)
+		switch {
+		case outcome == accepted:
+			return input, true
+		case outcome == rejected:
+			last := input.Cells[len(input.Cells)-1]
+			input.Error = &last
+			return input, false
+		}
+	}
+	return input, false
+}
+
+// Shortest list of terminals that takes a stack to acceptance. An item on top
+// of the stack is finished writing the shortest sentences of the rest of its
+// body and then reduced, until only the start symbol is left.
+func (m *machine) complete(stack []string) ([]string, bool) {
+	type config struct {
+		stack  []string
+		tokens []string
+	}
+
+	// Configurations by the amount of terminals written
+	buckets := [][]config{{{stack: stack}}}
+	seen := make(map[string]struct{})
+
+	for cost := 0; cost < len(buckets); cost++ {
+		for i := 0; i < len(buckets[cost]); i++ {
+			current := buckets[cost][i]
+			key := strings.Join(current.stack, " ")
+			if _, exist := seen[key]; exist {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			top := current.stack[len(current.stack)-1]
+			if move, ok := m.transit[top]["$"]; ok && move.MovementType == table.ACCEPT && len(current.stack) == 2 {
+				return current.tokens, true
+			}
+
+			for _, it := range m.items[top] {
+				production := m.productions[it.production]
+				if it.dot >= len(current.stack) {
+					continue
+				}
+				below := current.stack[len(current.stack)-1-it.dot]
+				jump, ok := m.gotos[below][production.Head.Value]
+				if !ok || !m.spells(current.stack[len(current.stack)-it.dot:], production.Body[:it.dot]) {
+					continue
+				}
+
+				written := make([]string, 0)
+				for _, symbol := range production.Body[it.dot:] {
+					if symbol.IsTerminal {
+						written = append(written, symbol.Value)
+					} else {
+						written = append(written, m.shortest[symbol.Value]...)
+					}
+				}
+				next := cost + len(written)
+				for len(buckets) <= next {
+					buckets = append(buckets, nil)
+				}
+				reduced := append(append([]string{}, current.stack[:len(current.stack)-it.dot]...), strconv.Itoa(jump.NextRow))
+				tokens := append(append([]string{}, current.tokens...), written...)
+				buckets[next] = append(buckets[next], config{stack: reduced, tokens: tokens})
+			}
+		}
+	}
+	return nil, false
+}
+
+// Whether the transitions into the states are the symbols
+func (m *machine) spells(states []string, symbols []parser.ParserSymbol) bool {
+	for i := range states {
+		if m.symbols[states[i]] != symbols[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Shortest sentence of terminals each non terminal derives. Missing for the ones
+// that never derive only terminals.
+func shortestYields(definition *parser.ParserDefinition) map[string][]string {
+	shortest := make(map[string][]string)
+
+	for changed := true; changed; {
+		changed = false
+		for _, production := range definition.Productions {
+			sentence, derivable := make([]string, 0), true
+			for _, symbol := range production.Body {
+				if symbol.IsTerminal {
+					sentence = append(sentence, symbol.Value)
+					continue
+				}
+				yield, exist := shortest[symbol.Value]
+				if !exist {
+					derivable = false
+					break
+				}
+				sentence = append(sentence, yield...)
+			}
+			if !derivable {
+				continue
+			}
+			if current, exist := shortest[production.Head.Value]; !exist || len(sentence) < len(current) {
+				shortest[production.Head.Value] = sentence
+				changed = true
+			}
+		}
+	}
+	return shortest
+}
+
+func unique(cells []Cell) []Cell {
+	set := make(map[Cell]struct{}, len(cells))
+	result := make([]Cell, 0, len(cells))
+	for _, c := range cells {
+		if _, exist := set[c]; !exist {
+			set[c] = struct{}{}
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Sorts states by their number
+func sortStates(states []string) {
+	sort.Slice(states, func(i, j int) bool {
+		a, _ := strconv.Atoi(states[i])
+		b, _ := strconv.Atoi(states[j])
+		return a < b
+	})
+}
+
+// Sorts cells by state number and then by symbol
+func sortCells(cells []Cell) {
+	sort.Slice(cells, func(i, j int) bool {
+		a, _ := strconv.Atoi(cells[i].State)
+		b, _ := strconv.Atoi(cells[j].State)
+		if a != b {
+			return a < b
+		}
+		return cells[i].Symbol < cells[j].Symbol
+	})
+}
+
+// Text writes the input using the first lexeme of each terminal, or its name
+// when it has none.
+func (c *Case) Text(lexemes map[string][]string, separator string) string {
+	words := make([]string, len(c.Tokens))
+	for i, token := range c.Tokens {
+		words[i] = token
+		if samples := lexemes[token]; len(samples) > 0 {
+			words[i] = samples[0]
+		}
+	}
+	return strings.Join(words, separator)
+}
@@ -0,0 +1,98 @@
+package fuzzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+	"github.com/DanielRasho/Parser/internal/Parser/automata"
+)
+
+func Test_cover(t *testing.T) {
+	for _, example := range []string{"superSimple", "simple", "medium", "hard"} {
+		definition, err := reader.Parse("../../../examples/" + example + ".par")
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := table.GetFirst(definition)
+		follow := table.GetFollow(definition, first)
+		transit, gotos, _ := table.NewTable(automata.NewAutomata(definition, false), first, follow, *definition)
+
+		coverage := Cover(definition, transit, gotos)
+		m := newMachine(definition, table.Deterministic(*transit), *gotos)
+		fmt.Printf("%s: %d accepted, %d rejected, %d missing\n", example, len(coverage.Valid), len(coverage.Invalid), len(coverage.Missing))
+
+		covered := make(map[Cell]struct{})
+		for _, input := range coverage.Valid {
+			if _, ok := m.run(input.Tokens); !ok {
+				t.Errorf("%s: %q is not accepted", example, strings.Join(input.Tokens, " "))
+			}
+			for _, c := range input.Cells {
+				covered[c] = struct{}{}
+			}
+		}
+
+		// Only reductions on a terminal of the FOLLOW set that never comes after
+		// the state can be missed.
+		missing := make(map[Cell]struct{})
+		for _, c := range coverage.Missing {
+			missing[c] = struct{}{}
+			if moves, ok := (*transit)[c.State][c.Symbol]; !ok || moves[0].MovementType != table.REDUCE {
+				t.Errorf("%s: no input goes through %v", example, c)
+			}
+		}
+		for state, row := range *transit {
+			for symbol := range row {
+				_, isCovered := covered[Cell{state, symbol}]
+				_, isMissing := missing[Cell{state, symbol}]
+				if isCovered == isMissing {
+					t.Errorf("%s: %v is covered and missing", example, Cell{state, symbol})
+				}
+			}
+		}
+
+		seen := make(map[Cell]struct{})
+		for _, input := range coverage.Invalid {
+			result, ok := m.run(input.Tokens)
+			if ok || *result.Error != *input.Error {
+				t.Errorf("%s: %q doesn't stop on %v", example, strings.Join(input.Tokens, " "), *input.Error)
+			}
+			if _, ok := (*transit)[input.Error.State][input.Error.Symbol]; ok {
+				t.Errorf("%s: %v is not empty", example, *input.Error)
+			}
+			if _, repeated := seen[*input.Error]; repeated {
+				t.Errorf("%s: %v has more than one input", example, *input.Error)
+			}
+			seen[*input.Error] = struct{}{}
+		}
+	}
+}
+
+func Test_coverSuperSimple(t *testing.T) {
+	definition, err := reader.Parse("../../../examples/superSimple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := table.GetFirst(definition)
+	follow := table.GetFollow(definition, first)
+	transit, gotos, _ := table.NewTable(automata.NewAutomata(definition, false), first, follow, *definition)
+
+	coverage := Cover(definition, transit, gotos)
+	if len(coverage.Missing) > 0 {
+		t.Errorf("expected every cell to be covered, missing %v", coverage.Missing)
+	}
+
+	// E → T + E | T and T → int * T | int | ( E ): every state can be followed by
+	// the end of the input or a wrong terminal.
+	texts := make(map[string]struct{})
+	for _, input := range coverage.Invalid {
+		texts[input.Text(nil, " ")] = struct{}{}
+	}
+	for _, text := range []string{"", "+", "int int", "int +", "( int"} {
+		if _, ok := texts[text]; !ok {
+			t.Errorf("expected %q between the rejected inputs", text)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package fuzzer
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+)
+
+// NewFuzzer computes how deep the shortest derivation of every production is.
+// The start symbol is the head of the first production, it fails when it can't
+// derive a sentence made only of terminals.
+func NewFuzzer(definition *parser.ParserDefinition) (*Fuzzer, error) {
+	if len(definition.Productions) == 0 {
+		return nil, fmt.Errorf("the grammar has no productions")
+	}
+	f := &Fuzzer{
+		definition:        definition,
+		start:             definition.Productions[0].Head,
+		heights:           make(map[string]int),
+		productionHeights: make([]int, len(definition.Productions)),
+	}
+
+	// A production is as deep as its deepest symbol plus one, a non terminal as
+	// its shallowest production. Repeated until nothing gets shallower.
+	for i := range f.productionHeights {
+		f.productionHeights[i] = -1
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, production := range definition.Productions {
+			height, derivable := 0, true
+			for _, symbol := range production.Body {
+				h, exist := f.height(symbol)
+				if !exist {
+					derivable = false
+					break
+				}
+				height = max(height, h)
+			}
+			if !derivable {
+				continue
+			}
+			height++
+			if f.productionHeights[i] == -1 || height < f.productionHeights[i] {
+				f.productionHeights[i] = height
+				changed = true
+			}
+			if current, exist := f.heights[production.Head.Value]; !exist || height < current {
+				f.heights[production.Head.Value] = height
+				changed = true
+			}
+		}
+	}
+
+	if _, exist := f.heights[f.start.Value]; !exist {
+		return nil, fmt.Errorf("%s never derives a sentence made only of terminals", f.start.Value)
+	}
+	return f, nil
+}
+
+// Depth of the shortest derivation of a symbol, 0 for terminals.
+func (f *Fuzzer) height(symbol parser.ParserSymbol) (int, bool) {
+	if symbol.IsTerminal {
+		return 0, true
+	}
+	height, exist := f.heights[symbol.Value]
+	return height, exist
+}
+
+// MinDepth is the depth of the shortest derivation of the whole grammar.
+func (f *Fuzzer) MinDepth() int {
+	return f.heights[f.start.Value]
+}
+
+// Generate derives a random sentence from the start symbol.
+func (f *Fuzzer) Generate(options Options) *Derivation {
+	return f.derive(f.start, max(options.MaxDepth, f.MinDepth()), options)
+}
+
+// Derives a symbol within a depth. Only the productions whose shortest derivation
+// fits are chosen, so the derivation always ends. Among them, the ones that leave
+// more room are more likely, which keeps sentences from growing until the limit.
+func (f *Fuzzer) derive(symbol parser.ParserSymbol, depth int, options Options) *Derivation {
+	if symbol.IsTerminal {
+		lexeme := symbol.Value
+		if lexemes := options.Lexemes[symbol.Value]; len(lexemes) > 0 {
+			lexeme = lexemes[options.Random.Intn(len(lexemes))]
+		}
+		return &Derivation{Symbol: symbol, Production: -1, Lexeme: lexeme}
+	}
+
+	candidates := make([]int, 0)
+	weights := make([]int, 0)
+	total := 0
+	for i, production := range f.definition.Productions {
+		height := f.productionHeights[i]
+		if production.Head.Value != symbol.Value || height == -1 || height > depth {
+			continue
+		}
+		candidates = append(candidates, i)
+		weights = append(weights, depth-height+1)
+		total += depth - height + 1
+	}
+
+	choice := options.Random.Intn(total)
+	production := candidates[len(candidates)-1]
+	for i, weight := range weights {
+		if choice < weight {
+			production = candidates[i]
+			break
+		}
+		choice -= weight
+	}
+
+	node := &Derivation{Symbol: symbol, Production: production}
+	for _, child := range f.definition.Productions[production].Body {
+		node.Children = append(node.Children, f.derive(child, depth-1, options))
+	}
+	return node
+}
+
+// Leaves returns the terminals of the derivation in order.
+func (d *Derivation) Leaves() []*Derivation {
+	if d.Production == -1 {
+		return []*Derivation{d}
+	}
+	leaves := make([]*Derivation, 0)
+	for _, child := range d.Children {
+		leaves = append(leaves, child.Leaves()...)
+	}
+	return leaves
+}
+
+// Writes the lexemes of the derivation joined by a separator.
+func (d *Derivation) Text(separator string) string {
+	leaves := d.Leaves()
+	lexemes := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		lexemes[i] = leaf.Lexeme
+	}
+	return strings.Join(lexemes, separator)
+}
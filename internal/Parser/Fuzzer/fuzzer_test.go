@@ -0,0 +1,109 @@
+package fuzzer
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	generator "github.com/DanielRasho/Parser/internal/Lexer/Generator"
+	yalex_reader "github.com/DanielRasho/Parser/internal/Lexer/Generator/YALexReader"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+)
+
+// Checks that every node is derived by a production of its symbol and that the
+// derivation is not deeper than allowed.
+func checkDerivation(t *testing.T, f *Fuzzer, d *Derivation, depth int) {
+	if d.Production == -1 {
+		if !d.Symbol.IsTerminal || len(d.Children) > 0 {
+			t.Errorf("unexpected leaf %v", d.Symbol)
+		}
+		return
+	}
+	if depth == 0 {
+		t.Fatalf("derivation deeper than allowed at %s", d.Symbol.Value)
+	}
+	production := f.definition.Productions[d.Production]
+	if production.Head.Value != d.Symbol.Value || len(production.Body) != len(d.Children) {
+		t.Fatalf("%s is not derived by %s", d.Symbol.Value, production.String())
+	}
+	for i, child := range d.Children {
+		if child.Symbol.Value != production.Body[i].Value {
+			t.Fatalf("%s is not derived by %s", d.Symbol.Value, production.String())
+		}
+		checkDerivation(t, f, child, depth-1)
+	}
+}
+
+func Test_generate(t *testing.T) {
+	definition, err := reader.Parse("../../../examples/medium.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFuzzer(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// program -> statement -> expression -> term -> factor -> ID
+	if f.MinDepth() != 5 {
+		t.Errorf("expected the shortest derivation to be 5 deep, got %d", f.MinDepth())
+	}
+
+	for _, depth := range []int{0, 5, 8, 15} {
+		options := Options{MaxDepth: depth, Random: rand.New(rand.NewSource(int64(depth)))}
+		for i := 0; i < 50; i++ {
+			d := f.Generate(options)
+			checkDerivation(t, f, d, max(depth, f.MinDepth()))
+		}
+		t.Log(depth, f.Generate(options).Text(" "))
+	}
+
+	// Same seed, same sentences
+	a := f.Generate(Options{MaxDepth: 10, Random: rand.New(rand.NewSource(1))}).Text(" ")
+	b := f.Generate(Options{MaxDepth: 10, Random: rand.New(rand.NewSource(1))}).Text(" ")
+	if a != b {
+		t.Errorf("expected the same sentence, got %q and %q", a, b)
+	}
+}
+
+func Test_unproductive(t *testing.T) {
+	definition, err := reader.ParseSource(io.NewSource("test.par", "%token A\n%%\ns: s A ;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFuzzer(definition); err == nil || !strings.Contains(err.Error(), "never derives") {
+		t.Errorf("expected an error for a grammar without sentences, got %v", err)
+	}
+}
+
+func Test_lexemes(t *testing.T) {
+	lex, err := yalex_reader.Parse("../../../examples/medium.lex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	automata, err := generator.BuildDFA(lex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewSource(1))
+	lexemes := Lexemes(lex, automata, dfa.SampleOptions{Count: 3, MaxLength: 8, Random: random})
+	if len(lexemes["LET"]) != 1 || lexemes["LET"][0] != "let" || len(lexemes["ID"]) == 0 {
+		t.Errorf("unexpected lexemes %v", lexemes)
+	}
+
+	definition, err := reader.Parse("../../../examples/medium.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := NewFuzzer(definition)
+	sentence := f.Generate(Options{MaxDepth: 8, Random: random, Lexemes: lexemes}).Text(" ")
+	t.Log(sentence)
+
+	// The lexer gives back the same tokens
+	for _, lexeme := range strings.Fields(sentence) {
+		if tokens := automata.Tokenize(lexeme); len(tokens) != 1 || tokens[0].Rule == -1 {
+			t.Errorf("%q is not a single token", lexeme)
+		}
+	}
+}
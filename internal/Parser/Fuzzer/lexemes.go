@@ -0,0 +1,25 @@
+package fuzzer
+
+import (
+	"regexp"
+
+	dfa "github.com/DanielRasho/Parser/internal/Lexer/DFA"
+	yalex_reader "github.com/DanielRasho/Parser/internal/Lexer/Generator/YALexReader"
+)
+
+// Token returned by the action of a lexer rule. Ex: { return ID }
+var returnedToken = regexp.MustCompile(`return\s+([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Lexemes samples the rules of a lexer, grouping the samples by the token their
+// action returns. The tokens of the lexer and the parser are matched by name.
+func Lexemes(definition *yalex_reader.YALexDefinition, automata *dfa.DFA, options dfa.SampleOptions) map[string][]string {
+	lexemes := make(map[string][]string)
+	for priority, rule := range definition.Rules {
+		match := returnedToken.FindStringSubmatch(rule.Action)
+		if match == nil {
+			continue // Skipped lexemes never reach the parser
+		}
+		lexemes[match[1]] = append(lexemes[match[1]], automata.Samples(priority, options)...)
+	}
+	return lexemes
+}
@@ -0,0 +1,62 @@
+package fuzzer
+
+import (
+	"math/rand"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+)
+
+// Generates random sentences of a grammar, derivations are kept within a depth
+// by only choosing productions that can still end on time.
+type Fuzzer struct {
+	definition *parser.ParserDefinition
+	start      parser.ParserSymbol
+	// Depth of the shortest derivation of each non terminal and each production
+	// (by its index). Missing when they can't derive only terminals.
+	heights           map[string]int
+	productionHeights []int
+}
+
+type Options struct {
+	// Maximum depth of a derivation, raised to the shortest derivation of the
+	// start symbol when lower
+	MaxDepth int
+	Random   *rand.Rand
+	// Concrete lexemes of each terminal by its name, terminals without lexemes
+	// are written by their name
+	Lexemes map[string][]string
+}
+
+// A node of the tree of a derivation. Leaves are terminals with their lexeme.
+type Derivation struct {
+	Symbol     parser.ParserSymbol
+	Production int // Index of the production used, -1 for terminals
+	Children   []*Derivation
+	Lexeme     string
+}
+
+// A cell of the transition table, or of the goto table when the symbol is a non
+// terminal.
+//
+//	{State: "4", Symbol: "+"}
+type Cell struct {
+	State  string
+	Symbol string // A terminal, $ or a non terminal
+}
+
+// An input and the cells the parser goes through reading it
+type Case struct {
+	Tokens []string
+	Cells  []Cell
+	// The empty cell the parser stops on, only for rejected inputs
+	Error *Cell
+}
+
+type Coverage struct {
+	// Accepted inputs, together they go through every cell that can be reached
+	Valid []Case
+	// One rejected input for each empty cell of the transition table reached
+	Invalid []Case
+	// Filled cells no accepted input goes through
+	Missing []Cell
+}
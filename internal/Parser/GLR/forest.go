@@ -0,0 +1,218 @@
+package glr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Count returns the number of parse trees in the forest. Derivations going
+// around a cycle of unit productions (A → B, B → A) are not counted.
+func (f *Forest) Count() int {
+	counts := make(map[*Node]int)
+	visiting := make(map[*Node]bool)
+	var count func(node *Node) int
+	count = func(node *Node) int {
+		if len(node.Packed) == 0 {
+			return 1
+		}
+		if visiting[node] {
+			return 0
+		}
+		if total, ok := counts[node]; ok {
+			return total
+		}
+		visiting[node] = true
+		total := 0
+		for _, packed := range node.Packed {
+			product := 1
+			for _, child := range packed.Children {
+				product *= count(child)
+			}
+			total += product
+		}
+		visiting[node] = false
+		counts[node] = total
+		return total
+	}
+	return count(f.Root)
+}
+
+// Ambiguities lists the nodes with more than one alternative, the ones covering
+// more tokens first.
+func (f *Forest) Ambiguities() []*Node {
+	ambiguous := make([]*Node, 0)
+	f.walk(func(node *Node) {
+		if len(node.Packed) > 1 {
+			ambiguous = append(ambiguous, node)
+		}
+	})
+	sort.SliceStable(ambiguous, func(i, j int) bool {
+		a, b := ambiguous[i], ambiguous[j]
+		if a.End-a.Start != b.End-b.Start {
+			return a.End-a.Start > b.End-b.Start
+		}
+		return a.Start < b.Start
+	})
+	return ambiguous
+}
+
+// Disambiguate applies the filters to every ambiguous node, children before
+// their parents, dropping the alternatives they don't keep. A filter that would
+// drop every alternative of a node is ignored there. The forest is changed in place.
+func (f *Forest) Disambiguate(filters ...Filter) {
+	visited := make(map[*Node]bool)
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if visited[node] {
+			return
+		}
+		visited[node] = true
+		for _, packed := range node.Packed {
+			for _, child := range packed.Children {
+				visit(child)
+			}
+		}
+		for _, filter := range filters {
+			if len(node.Packed) < 2 {
+				break
+			}
+			if kept := filter(node, node.Packed); len(kept) > 0 {
+				node.Packed = kept
+			}
+		}
+	}
+	visit(f.Root)
+}
+
+// Tree returns the only parse tree of the forest. When there are more, it fails
+// with an *AmbiguityError for the ambiguous node covering the most tokens.
+func (f *Forest) Tree() (*Tree, error) {
+	if ambiguous := f.Ambiguities(); len(ambiguous) > 0 {
+		return nil, &AmbiguityError{Node: ambiguous[0]}
+	}
+	visiting := make(map[*Node]bool)
+	var build func(node *Node) (*Tree, error)
+	build = func(node *Node) (*Tree, error) {
+		if len(node.Packed) == 0 {
+			return &Tree{Symbol: node.Symbol, Production: -1, Start: node.Start, End: node.End}, nil
+		}
+		// Only left when a filter drops the alternatives leaving the cycle
+		if visiting[node] {
+			return nil, fmt.Errorf("%s derives itself on tokens %d to %d, it has no finite tree", node.Symbol.Value, node.Start, node.End)
+		}
+		visiting[node] = true
+		defer delete(visiting, node)
+
+		packed := node.Packed[0]
+		tree := &Tree{Symbol: node.Symbol, Production: packed.Production, Start: node.Start, End: node.End}
+		for _, child := range packed.Children {
+			subtree, err := build(child)
+			if err != nil {
+				return nil, err
+			}
+			tree.Children = append(tree.Children, subtree)
+		}
+		return tree, nil
+	}
+	return build(f.Root)
+}
+
+// Calls a function once for each node reachable from the root.
+func (f *Forest) walk(do func(node *Node)) {
+	visited := make(map[*Node]bool)
+	pending := []*Node{f.Root}
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+		do(node)
+		for _, packed := range node.Packed {
+			pending = append(pending, packed.Children...)
+		}
+	}
+}
+
+// Prefer keeps the alternatives built with one of the productions, when there is any.
+func Prefer(productions ...int) Filter {
+	return func(node *Node, alternatives []*Packed) []*Packed {
+		kept := make([]*Packed, 0)
+		for _, packed := range alternatives {
+			if contains(productions, packed.Production) {
+				kept = append(kept, packed)
+			}
+		}
+		return kept
+	}
+}
+
+// Priority drops the alternatives built with the higher production that have a
+// child built with the lower one. Ex: with E → E * E over E → E + E, 1 + 2 * 3
+// is not read as (1 + 2) * 3.
+func Priority(higher, lower int) Filter {
+	return func(node *Node, alternatives []*Packed) []*Packed {
+		kept := make([]*Packed, 0)
+		for _, packed := range alternatives {
+			dropped := false
+			if packed.Production == higher {
+				for _, child := range packed.Children {
+					if builtWith(child, []int{lower}) {
+						dropped = true
+					}
+				}
+			}
+			if !dropped {
+				kept = append(kept, packed)
+			}
+		}
+		return kept
+	}
+}
+
+// LeftAssociative drops the alternatives built with one of the productions
+// whose last child is built with one of them too. Ex: 1 - 2 + 3 is read as (1 - 2) + 3
+func LeftAssociative(productions ...int) Filter {
+	return associative(productions, func(packed *Packed) *Node { return packed.Children[len(packed.Children)-1] })
+}
+
+// RightAssociative drops the alternatives built with one of the productions
+// whose first child is built with one of them too. Ex: 2 ^ 3 ^ 2 is read as 2 ^ (3 ^ 2)
+func RightAssociative(productions ...int) Filter {
+	return associative(productions, func(packed *Packed) *Node { return packed.Children[0] })
+}
+
+func associative(productions []int, operand func(packed *Packed) *Node) Filter {
+	return func(node *Node, alternatives []*Packed) []*Packed {
+		kept := make([]*Packed, 0)
+		for _, packed := range alternatives {
+			if !contains(productions, packed.Production) || !builtWith(operand(packed), productions) {
+				kept = append(kept, packed)
+			}
+		}
+		return kept
+	}
+}
+
+// Whether every alternative of a non terminal node uses one of the productions.
+func builtWith(node *Node, productions []int) bool {
+	if len(node.Packed) == 0 {
+		return false
+	}
+	for _, packed := range node.Packed {
+		if !contains(productions, packed.Production) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(productions []int, production int) bool {
+	for _, p := range productions {
+		if p == production {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package glr
+
+import (
+	"sort"
+	"strconv"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+// NewParser builds a GLR parser over the tables of table.NewTable, which may
+// have conflicts. The grammar can't have ε-productions, the automata doesn't
+// support them.
+func NewParser(definition *parser.ParserDefinition, transit table.TransitionTbl, gotos table.GotoTbl) *Parser {
+	return &Parser{definition: definition, transit: transit, gotos: gotos}
+}
+
+// Parse reads a list of terminals and returns the forest of all its parses.
+//
+// Tokens are read one at a time by every stack at once. Stack nodes reached
+// after reading the same tokens are kept in a level, one per state. At each
+// level every reduction is done first, they add nodes and edges to the level
+// which can reduce again, then the stacks able to shift the token move to the
+// next level and the rest die.
+func (p *Parser) Parse(tokens []string) (*Forest, error) {
+	terminals := make(map[string]parser.ParserSymbol, len(p.definition.Terminals))
+	for _, terminal := range p.definition.Terminals {
+		terminals[terminal.Value] = terminal
+	}
+
+	start := &stackNode{state: "0", level: 0}
+	level := []*stackNode{start}
+	nodes := make(map[nodeKey]*Node)
+
+	for i := 0; ; i++ {
+		lookahead := "$"
+		if i < len(tokens) {
+			lookahead = tokens[i]
+		}
+		level = p.reduce(level, lookahead, i, nodes)
+
+		if i == len(tokens) {
+			for _, top := range level {
+				for _, move := range p.transit[top.state]["$"] {
+					if move.MovementType != table.ACCEPT {
+						continue
+					}
+					for _, edge := range top.edges {
+						if edge.to == start {
+							return &Forest{Root: edge.label, Tokens: tokens}, nil
+						}
+					}
+				}
+			}
+			return nil, &SyntaxError{Position: i, Token: lookahead, Expected: p.expected(level)}
+		}
+
+		leaf := &Node{Symbol: terminals[lookahead], Start: i, End: i + 1}
+		next := make([]*stackNode, 0)
+		for _, top := range level {
+			for _, move := range p.transit[top.state][lookahead] {
+				if move.MovementType != table.SHIFT {
+					continue
+				}
+				state := strconv.Itoa(move.NextRow)
+				shifted := find(next, state)
+				if shifted == nil {
+					shifted = &stackNode{state: state, level: i + 1}
+					next = append(next, shifted)
+				}
+				shifted.addEdge(top, leaf)
+			}
+		}
+		if len(next) == 0 {
+			return nil, &SyntaxError{Position: i, Token: lookahead, Expected: p.expected(level)}
+		}
+		level = next
+	}
+}
+
+// Does every reduction possible on the nodes of a level, returning the level
+// with the nodes they add. A reduction of a production pops its body from every
+// path starting on an edge, so when an edge is added to a node only the paths
+// through it are reduced again.
+func (p *Parser) reduce(level []*stackNode, lookahead string, position int, nodes map[nodeKey]*Node) []*stackNode {
+	pending := make([]reduction, 0)
+	enqueue := func(node *stackNode, edge *stackEdge) {
+		for _, move := range p.transit[node.state][lookahead] {
+			if move.MovementType == table.REDUCE {
+				pending = append(pending, reduction{node: node, edge: edge, production: move.NextRow})
+			}
+		}
+	}
+	for _, node := range level {
+		for _, edge := range node.edges {
+			enqueue(node, edge)
+		}
+	}
+
+	for len(pending) > 0 {
+		r := pending[0]
+		pending = pending[1:]
+		production := p.definition.Productions[r.production]
+
+		for _, path := range r.edge.paths(len(production.Body)) {
+			key := nodeKey{symbol: production.Head.Value, start: path.to.level, end: position}
+			symbol, ok := nodes[key]
+			if !ok {
+				symbol = &Node{Symbol: production.Head, Start: path.to.level, End: position}
+				nodes[key] = symbol
+			}
+			symbol.addPacked(r.production, path.labels)
+
+			move, ok := p.gotos[path.to.state][production.Head.Value]
+			if !ok {
+				continue
+			}
+			state := strconv.Itoa(move.NextRow)
+			target := find(level, state)
+			if target == nil {
+				target = &stackNode{state: state, level: position}
+				level = append(level, target)
+			}
+			// The edge and its label were there, the new alternative is already on the label
+			if target.hasEdge(path.to) {
+				continue
+			}
+			enqueue(target, target.addEdge(path.to, symbol))
+		}
+	}
+	return level
+}
+
+// Terminals some node of the level has a movement for, sorted.
+func (p *Parser) expected(level []*stackNode) []string {
+	set := make(map[string]struct{})
+	for _, node := range level {
+		for symbol := range p.transit[node.state] {
+			set[symbol] = struct{}{}
+		}
+	}
+	expected := make([]string, 0, len(set))
+	for symbol := range set {
+		expected = append(expected, symbol)
+	}
+	sort.Strings(expected)
+	return expected
+}
+
+// The symbols popped by a reduction and the node it uncovers.
+type stackPath struct {
+	to     *stackNode
+	labels []*Node // In the order of the production's body
+}
+
+// Paths of a number of edges starting with this one.
+func (e *stackEdge) paths(length int) []stackPath {
+	if length == 1 {
+		return []stackPath{{to: e.to, labels: []*Node{e.label}}}
+	}
+	paths := make([]stackPath, 0)
+	for _, next := range e.to.edges {
+		for _, rest := range next.paths(length - 1) {
+			labels := append(append([]*Node{}, rest.labels...), e.label)
+			paths = append(paths, stackPath{to: rest.to, labels: labels})
+		}
+	}
+	return paths
+}
+
+func (n *stackNode) addEdge(to *stackNode, label *Node) *stackEdge {
+	edge := &stackEdge{to: to, label: label}
+	n.edges = append(n.edges, edge)
+	return edge
+}
+
+// Without ε-productions both nodes tell the symbol and tokens of the label.
+func (n *stackNode) hasEdge(to *stackNode) bool {
+	for _, edge := range n.edges {
+		if edge.to == to {
+			return true
+		}
+	}
+	return false
+}
+
+func find(level []*stackNode, state string) *stackNode {
+	for _, node := range level {
+		if node.state == state {
+			return node
+		}
+	}
+	return nil
+}
+
+// Adds a way of deriving the node unless it has it already.
+func (n *Node) addPacked(production int, children []*Node) {
+	for _, packed := range n.Packed {
+		if packed.Production != production || len(packed.Children) != len(children) {
+			continue
+		}
+		same := true
+		for i := range children {
+			if packed.Children[i] != children[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+	n.Packed = append(n.Packed, &Packed{Production: production, Children: children})
+}
@@ -0,0 +1,194 @@
+package glr
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	fuzzer "github.com/DanielRasho/Parser/internal/Parser/Fuzzer"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+	"github.com/DanielRasho/Parser/internal/Parser/automata"
+)
+
+const ambiguous = `%token int + * ^
+%%
+E: E + E | E * E | E ^ E | int ;`
+
+func newParser(t *testing.T, definition *parser.ParserDefinition) (*Parser, table.TransitionTbl) {
+	first := table.GetFirst(definition)
+	follow := table.GetFollow(definition, first)
+	transit, gotos, err := table.NewTable(automata.NewAutomata(definition, false), first, follow, *definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewParser(definition, *transit, *gotos), *transit
+}
+
+func parse(t *testing.T, content string) *parser.ParserDefinition {
+	definition, err := reader.ParseSource(io.NewSource("ambiguous.par", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return definition
+}
+
+func Test_ambiguous(t *testing.T) {
+	definition := parse(t, ambiguous)
+	p, transit := newParser(t, definition)
+	conflicts := table.Conflicts(transit)
+	if len(conflicts) == 0 {
+		t.Fatal("expected conflicts on the table")
+	}
+	fmt.Println(conflicts[0].Describe(definition))
+
+	// The number of ways to group n operands
+	counts := []struct {
+		input string
+		trees int
+	}{
+		{"int", 1},
+		{"int + int", 1},
+		{"int + int * int", 2},
+		{"int + int + int + int", 5},
+		{"int * int + int ^ int + int", 14},
+	}
+	for _, c := range counts {
+		forest, err := p.Parse(strings.Fields(c.input))
+		if err != nil {
+			t.Fatalf("%s: %s", c.input, err)
+		}
+		if forest.Count() != c.trees {
+			t.Errorf("%s: expected %d trees, got %d", c.input, c.trees, forest.Count())
+		}
+	}
+
+	forest, _ := p.Parse(strings.Fields("int + int * int"))
+	var ambiguity *AmbiguityError
+	if _, err := forest.Tree(); !errors.As(err, &ambiguity) || ambiguity.Node.Start != 0 || ambiguity.Node.End != 5 {
+		t.Errorf("expected the whole input to be ambiguous, got %v", err)
+	}
+}
+
+func Test_filters(t *testing.T) {
+	p, _ := newParser(t, parse(t, ambiguous))
+	// Productions: 0 E + E, 1 E * E, 2 E ^ E, 3 int
+	filters := []Filter{
+		Priority(1, 0), Priority(2, 0), Priority(2, 1),
+		LeftAssociative(0), LeftAssociative(1), RightAssociative(2),
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"int + int * int", "E(E(int) + E(E(int) * E(int)))"},
+		{"int + int + int", "E(E(E(int) + E(int)) + E(int))"},
+		{"int ^ int ^ int", "E(E(int) ^ E(E(int) ^ E(int)))"},
+		{"int * int ^ int + int", "E(E(E(int) * E(E(int) ^ E(int))) + E(int))"},
+	}
+	for _, c := range cases {
+		forest, err := p.Parse(strings.Fields(c.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		forest.Disambiguate(filters...)
+		tree, err := forest.Tree()
+		if err != nil {
+			t.Errorf("%s: %s", c.input, err)
+			continue
+		}
+		if tree.String() != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.input, c.expected, tree)
+		}
+	}
+
+	forest, _ := p.Parse(strings.Fields("int + int * int"))
+	forest.Disambiguate(Prefer(1))
+	if tree, err := forest.Tree(); err != nil || tree.String() != "E(E(E(int) + E(int)) * E(int))" {
+		t.Errorf("unexpected tree %v, %v", tree, err)
+	}
+}
+
+func Test_syntaxError(t *testing.T) {
+	p, _ := newParser(t, parse(t, ambiguous))
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"int +", `unexpected end of input, expected one of: int`},
+		{"int int", `unexpected "int" at token 1, expected one of: $ * + ^`},
+		{"", `unexpected end of input, expected one of: int`},
+	}
+	for _, c := range cases {
+		_, err := p.Parse(strings.Fields(c.input))
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("%q: expected %q, got %v", c.input, c.expected, err)
+		}
+	}
+}
+
+func Test_cycle(t *testing.T) {
+	// A → B → A derives the same tokens forever, only the way out is counted
+	p, _ := newParser(t, parse(t, "%token x\n%%\nS: A ;\nA: B | x ;\nB: A ;"))
+	forest, err := p.Parse([]string{"x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forest.Count() != 1 {
+		t.Errorf("expected 1 tree, got %d", forest.Count())
+	}
+	forest.Disambiguate(Prefer(2))
+	if tree, err := forest.Tree(); err != nil || tree.String() != "S(A(x))" {
+		t.Errorf("unexpected tree %v, %v", tree, err)
+	}
+}
+
+// SLR grammars have a single parse, which must give back the input.
+func Test_examples(t *testing.T) {
+	for _, example := range []string{"superSimple", "simple", "medium", "hard"} {
+		definition, err := reader.Parse("../../../examples/" + example + ".par")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, _ := newParser(t, definition)
+		f, err := fuzzer.NewFuzzer(definition)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		random := rand.New(rand.NewSource(48))
+		for i := 0; i < 100; i++ {
+			sentence := strings.Fields(f.Generate(fuzzer.Options{MaxDepth: 7, Random: random}).Text(" "))
+			forest, err := p.Parse(sentence)
+			if err != nil {
+				t.Errorf("%s: %q: %s", example, strings.Join(sentence, " "), err)
+				break
+			}
+			tree, err := forest.Tree()
+			if err != nil {
+				t.Errorf("%s: %q: %s", example, strings.Join(sentence, " "), err)
+				break
+			}
+			if leaves := strings.Join(leaves(tree), " "); leaves != strings.Join(sentence, " ") {
+				t.Errorf("%s: expected %q, got %q", example, strings.Join(sentence, " "), leaves)
+				break
+			}
+		}
+	}
+}
+
+func leaves(tree *Tree) []string {
+	if tree.Production < 0 {
+		return []string{tree.Symbol.Value}
+	}
+	values := make([]string, 0)
+	for _, child := range tree.Children {
+		values = append(values, leaves(child)...)
+	}
+	return values
+}
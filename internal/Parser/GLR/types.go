@@ -0,0 +1,121 @@
+package glr
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+// Parser follows every movement of the cells with a conflict, keeping a stack
+// for each way of reading the input. Stacks are merged into a graph (the graph
+// structured stack) so the parses share the states and symbols they have in common.
+type Parser struct {
+	definition *parser.ParserDefinition
+	transit    table.TransitionTbl
+	gotos      table.GotoTbl
+}
+
+// Node of the graph structured stack: a state of the automata reached after
+// reading the tokens before level. Edges go back to nodes of earlier levels,
+// labeled with the forest node of the symbol read in between.
+type stackNode struct {
+	state string
+	level int
+	edges []*stackEdge
+}
+
+type stackEdge struct {
+	to    *stackNode
+	label *Node
+}
+
+// Reduction of a production going first through an edge of a stack node.
+type reduction struct {
+	node       *stackNode
+	edge       *stackEdge
+	production int
+}
+
+// Shared packed parse forest, every parse of the input. A symbol covering the
+// same tokens has a single node in all of them, holding each way it derives them.
+//
+//	int + int + int, with E → E + E | int
+//	E[0,5] ─┬─ E[0,3] + E[4,5]
+//	        └─ E[0,1] + E[2,5]
+type Forest struct {
+	Root   *Node
+	Tokens []string
+}
+
+// A symbol deriving the tokens from Start to End (not included).
+type Node struct {
+	Symbol parser.ParserSymbol
+	Start  int
+	End    int
+	// Productions deriving the tokens, more than one when it is ambiguous. Empty for terminals.
+	Packed []*Packed
+}
+
+// One way of deriving the tokens of a node.
+type Packed struct {
+	Production int // Index on ParserDefinition.Productions
+	Children   []*Node
+}
+
+// Forest nodes are shared by the symbol and the tokens they cover.
+type nodeKey struct {
+	symbol string
+	start  int
+	end    int
+}
+
+// A Filter gets the alternatives of an ambiguous node and returns the ones to keep.
+type Filter func(node *Node, alternatives []*Packed) []*Packed
+
+// A parse tree taken out of the forest.
+type Tree struct {
+	Symbol     parser.ParserSymbol
+	Production int // Index on ParserDefinition.Productions, -1 for terminals
+	Start      int
+	End        int
+	Children   []*Tree
+}
+
+// Writes the tree with the children of each non terminal between parenthesis. Ex: E(E(int) + E(int))
+func (t *Tree) String() string {
+	if t.Production < 0 {
+		return t.Symbol.Value
+	}
+	children := make([]string, len(t.Children))
+	for i, child := range t.Children {
+		children[i] = child.String()
+	}
+	return t.Symbol.Value + "(" + strings.Join(children, " ") + ")"
+}
+
+// SyntaxError is returned by Parse when no stack can read a token.
+type SyntaxError struct {
+	Position int    // Index of the token, the length of the input for its end
+	Token    string // "$" for the end of the input
+	Expected []string
+}
+
+func (e *SyntaxError) Error() string {
+	found := fmt.Sprintf("%q at token %d", e.Token, e.Position)
+	if e.Token == "$" {
+		found = "end of input"
+	}
+	return fmt.Sprintf("unexpected %s, expected one of: %s", found, strings.Join(e.Expected, " "))
+}
+
+// AmbiguityError is returned by Forest.Tree when a node still has more than one alternative.
+type AmbiguityError struct {
+	Node *Node
+}
+
+func (e *AmbiguityError) Error() string {
+	return fmt.Sprintf("the input is ambiguous, %s derives tokens %d to %d in %d ways",
+		e.Node.Symbol.Value, e.Node.Start, e.Node.End, len(e.Node.Packed))
+}
@@ -1,159 +1,298 @@
 package reader
 
 import (
-	"strings"
-
 	io "github.com/DanielRasho/Parser/internal/IO"
 	Parser "github.com/DanielRasho/Parser/internal/Parser"
 )
 
-// TODO: Lo que tienes que implementar andre
+// Parse reads a yapar file into a ParserDefinition. All the errors found are
+// returned together as io.Diagnostics, each pointing to its line and column.
 func Parse(filePath string) (*Parser.ParserDefinition, error) {
+	source, err := io.ReadSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSource(source)
+}
+
+// ParseSource reads a yapar definition already loaded in memory. The file has 2 sections
+// separated by "%%", comments "/* */" can be placed anywhere:
+//
+//	%token A B
+//	IGNORE WS
+//	%channel comments COMMENT
+//	%%
+//	head:
+//	    A head
+//	  | B
+//	  | %empty      /* ε-production */
+//	;
+func ParseSource(source *io.Source) (*Parser.ParserDefinition, error) {
+	s := newScanner(source)
+
+	tokens, err := parseDeclarations(s)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseRules(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDefinition(s, tokens, rules)
+}
+
+// Written as the only symbol of an alternative to derive the empty string
+const EMPTY_KEYWORD = "%empty"
+
+// A %token, IGNORE or %channel declaration
+type tokenDeclaration struct {
+	word word
+	// Channel the token is routed to, tokens off the default channel never reach the parser
+	channel string
+}
+
+// A rule with all its alternatives: "head: a b | c ;"
+type rule struct {
+	head         word
+	alternatives [][]word
+	// Offset of the ":" used to point empty rules
+	colon int
+}
+
+// Reads all %token, IGNORE and %channel lines until the "%%" separator.
+//
+//	%token A B              tokens on the default channel, the ones the parser consumes
+//	IGNORE WS               same as "%channel hidden WS"
+//	%channel comments C     tokens on a named channel
+func parseDeclarations(s *scanner) ([]tokenDeclaration, error) {
+	declarations := make([]tokenDeclaration, 0)
+
+	for {
+		w, err := s.next()
+		if err != nil {
+			return nil, err
+		}
 
-	var line string
-	var token []string
-	var Tokens []Parser.ParserSymbol
-	var Productions *Parser.ParserProduction = new(Parser.ParserProduction)
-	var arrProductions []Parser.ParserProduction
-	var nonterminals []Parser.ParserSymbol
-	ignoredTokens := make(map[int]Parser.ParserSymbol)
-	var is_product = false
-	var head string
-	var err error
-
-	tokensReaded := 0
-
-	filereader, _ := io.ReadFile(filePath)
-	for filereader.NextLine(&line) {
-
-		//Starts parsing tokens
-		if strings.Contains(line, "%token") && !is_product {
-			token = strings.Split(line, "%token")
-			token = strings.Split(token[1], " ")
-			for i := 1; i < len(token); i++ {
-				token[i] = strings.TrimSpace(token[i])
-				Tokens = append(Tokens, Parser.ParserSymbol{Id: tokensReaded, Value: token[i], IsTerminal: true})
-				tokensReaded++
+		switch w.text {
+		case "":
+			return nil, s.errorf(w.offset, "missing %%%% separator between tokens and productions")
+		case "%%":
+			return declarations, nil
+		case "%token", "IGNORE", "%channel":
+			names, err := s.restOfLine()
+			if err != nil {
+				return nil, err
 			}
-		} else if strings.Contains(line, "IGNORE") && !is_product {
-			token = strings.Split(line, "IGNORE")
-			token = strings.Split(token[1], " ")
-			for i := 1; i < len(token); i++ {
-				token[i] = strings.TrimSpace(token[i])
-				ignoredTokens[tokensReaded] = Parser.ParserSymbol{Id: tokensReaded, Value: token[i], IsTerminal: true}
-				tokensReaded++
+
+			channel := Parser.DEFAULT_CHANNEL
+			if w.text == "IGNORE" {
+				channel = Parser.HIDDEN_CHANNEL
+			} else if w.text == "%channel" {
+				if len(names) == 0 {
+					return nil, s.errorf(w.offset, "%%channel declaration without a name")
+				}
+				channel = names[0].text
+				if channel == Parser.DEFAULT_CHANNEL {
+					return nil, s.errorf(names[0].offset, "tokens on the default channel are declared with %%token")
+				}
+				names = names[1:]
+			}
+
+			if len(names) == 0 {
+				return nil, s.errorf(w.offset, "%s declaration without tokens", w.text)
+			}
+			for _, name := range names {
+				declarations = append(declarations, tokenDeclaration{word: name, channel: channel})
 			}
+		default:
+			return nil, s.errorf(w.offset, "unexpected %q, expected %%token, IGNORE, %%channel or %%%%", w.text)
 		}
+	}
+}
 
-		//Una vez terminado de leer los tokens terminales empezamos a leer las producciones
-		if is_product {
-
-			//Aqui es donde se lee las producciones y termina cuando no hay mas producciones a leer si tiene el simbolo ; entonces se termina
-			if head != "" {
-				line = strings.TrimSpace(line)
-				if line != "" {
-
-					if !strings.Contains(line, ";") {
-						if strings.Contains(line, "|") {
-
-							arrProductions = append(arrProductions, *Productions)
-							samehead := Productions.Head
-							Productions = &Parser.ParserProduction{Head: samehead, Id: (len(arrProductions) + 1)}
-
-							// fmt.Println("array", Productions.Body)
-
-							line = strings.Split(line, "|")[1]
-							line = strings.TrimSpace(line)
-						}
-
-						token = strings.Split(line, " ")
-						nonTerminalIndexCounter := -1
-
-						for i := range len(token) {
-							index_val := findIndex(Tokens, token[i])
-							if index_val == -1 {
-
-								index_valnon := findIndex(nonterminals, token[i])
-								if index_valnon == -1 {
-									nonterminals = append(nonterminals, Parser.ParserSymbol{Id: -1, Value: token[i]})
-									index_valnon = findIndex(nonterminals, token[i])
-									nonTerminalIndexCounter--
-								}
-								Productions.Body = append(Productions.Body, nonterminals[index_valnon])
-								Productions.Id = len(arrProductions) + 1
-							} else {
-								Productions.Id = len(arrProductions) + 1
-								Productions.Body = append(Productions.Body, Tokens[index_val])
-							}
-
-						}
-					} else {
-						arrProductions = append(arrProductions, *Productions)
-						// fmt.Println("array2", arrProductions)
-						head = ""
-					}
+// Reads every rule until the end of the file.
+func parseRules(s *scanner) ([]rule, error) {
+	rules := make([]rule, 0)
+	diagnostics := make(io.Diagnostics, 0)
 
-				}
+	head, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+
+	for head.text != "" {
+		if head.isPunctuation() {
+			return nil, s.errorf(head.offset, "expected the head of a rule, found %q", head.text)
+		}
+		colon, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if colon.text != ":" {
+			return nil, s.errorf(colon.offset, "expected : after %s", head.text)
+		}
+
+		current := rule{head: head, alternatives: make([][]word, 0), colon: colon.offset}
+		alternative := make([]word, 0)
+		lastEnd := colon.end()
 
+		for {
+			w, err := s.next()
+			if err != nil {
+				return nil, err
 			}
 
-			//Lee la primera produccion y luego de eso si tiene un valor entonces se agrega al parser y las producciones en otro lado
-			if strings.Contains(line, ":") {
-				head = strings.Split(line, ":")[0]
-				index_val := findIndex(Tokens, head)
-				if index_val == -1 {
+			// Missing ";", the file ended or a new rule "head:" begins.
+			if w.text == "" || (!w.isPunctuation() && s.peekColon()) {
+				diagnostics = append(diagnostics, s.errorf(lastEnd, "missing ; at the end of rule %s", current.head.text))
+				current.alternatives = append(current.alternatives, alternative)
+				head = w
+				break
+			}
 
-					Productions = new(Parser.ParserProduction)
-					Productions.Head = Parser.ParserSymbol{Id: -1, Value: head}
+			if w.text == "|" || w.text == ";" {
+				current.alternatives = append(current.alternatives, alternative)
+				alternative = make([]word, 0)
+				if w.text == ";" {
+					head, err = s.next()
+					if err != nil {
+						return nil, err
+					}
+					break
 				}
+				continue
+			}
+			if w.text == ":" {
+				return nil, s.errorf(w.offset, "unexpected :, rule %s is not closed with ;", current.head.text)
 			}
 
-		}
-		//Inicializamos el lugar donde empieza a leer las producciones
-		if strings.Contains(line, "%%") {
-			is_product = true
+			alternative = append(alternative, w)
+			lastEnd = w.end()
 		}
 
+		rules = append(rules, current)
 	}
-	for i := range len(Tokens) {
-		if Tokens[i].Id == -1 {
-			nonterminals = append(nonterminals, Tokens[i])
-		}
+
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
 	}
-	for i := 0; i < len(arrProductions); i++ {
-		if !AlreadyinList(nonterminals, arrProductions[i].Head) {
-			nonterminals = append(nonterminals, arrProductions[i].Head)
+	return rules, nil
+}
+
+// Validates the symbols read and builds the final definition.
+func buildDefinition(s *scanner, declarations []tokenDeclaration, rules []rule) (*Parser.ParserDefinition, error) {
+	diagnostics := make(io.Diagnostics, 0)
+
+	terminals := make([]Parser.ParserSymbol, 0)
+	ignored := make(map[int]Parser.ParserSymbol)
+	channels := make(map[int]string)
+	positions := make(map[int]io.Position)
+	declared := make(map[string]tokenDeclaration)
 
+	for i, declaration := range declarations {
+		name := declaration.word.text
+		if _, exist := declared[name]; exist {
+			diagnostics = append(diagnostics, s.errorf(declaration.word.offset, "token %s is declared more than once", name))
+			continue
 		}
+		declared[name] = declaration
+		positions[i] = s.src.Position(declaration.word.offset)
 
+		symbol := Parser.ParserSymbol{Id: i, Value: name, IsTerminal: true}
+		if declaration.channel != Parser.DEFAULT_CHANNEL {
+			ignored[i] = symbol
+			channels[i] = declaration.channel
+		} else {
+			terminals = append(terminals, symbol)
+		}
 	}
 
-	return &Parser.ParserDefinition{
-		NonTerminals:  nonterminals,
-		Terminals:     Tokens,
-		Productions:   arrProductions,
-		IgnoredSymbol: ignoredTokens,
-	}, err
-}
+	if len(rules) == 0 {
+		diagnostics = append(diagnostics, s.errorf(len(s.src.Content), "the grammar has no productions"))
+		return nil, diagnostics
+	}
 
-// FINDS THE INDEX VALUE OF THE ARRAY OF PARSE SYMBOLS
-func findIndex(tokens []Parser.ParserSymbol, target string) int {
-	for i, token := range tokens {
-		if token.Value == target {
-			return i
+	nonTerminals := make([]Parser.ParserSymbol, 0)
+	heads := make(map[string]Parser.ParserSymbol)
+	for _, r := range rules {
+		name := r.head.text
+		if _, isToken := declared[name]; isToken {
+			diagnostics = append(diagnostics, s.errorf(r.head.offset, "%s is declared as a token, it cannot be the head of a rule", name))
+			continue
+		}
+		if _, exist := heads[name]; !exist {
+			heads[name] = Parser.ParserSymbol{Id: Parser.NON_TERMINAL_ID, Value: name}
+			nonTerminals = append(nonTerminals, heads[name])
 		}
 	}
-	return -1 // not found
-}
 
-// FINDS THE INDEX VALUE OF THE ARRAY OF PARSE SYMBOLS
-func AlreadyinList(nonterminals []Parser.ParserSymbol, target Parser.ParserSymbol) bool {
+	productions := make([]Parser.ParserProduction, 0)
+	for _, r := range rules {
+		if len(r.alternatives) == 1 && len(r.alternatives[0]) == 0 {
+			diagnostics = append(diagnostics, s.errorf(r.colon, "rule %s has no alternatives", r.head.text))
+			continue
+		}
 
-	for i := 0; i < len(nonterminals); i++ {
-		if nonterminals[i].Id == target.Id && nonterminals[i].IsTerminal == target.IsTerminal && nonterminals[i].Value == target.Value {
-			return true
+		for _, alternative := range r.alternatives {
+			if len(alternative) == 0 {
+				diagnostics = append(diagnostics, s.errorf(r.colon, "rule %s has an empty alternative, write %%empty for an ε-production", r.head.text))
+				continue
+			}
+			if len(alternative) == 1 && alternative[0].text == EMPTY_KEYWORD {
+				alternative = alternative[:0]
+			}
+
+			body := make([]Parser.ParserSymbol, 0, len(alternative))
+			for _, w := range alternative {
+				if w.text == EMPTY_KEYWORD {
+					diagnostics = append(diagnostics, s.errorf(w.offset, "%%empty must be alone in its alternative"))
+				} else if declaration, isToken := declared[w.text]; isToken {
+					if declaration.channel == Parser.HIDDEN_CHANNEL {
+						diagnostics = append(diagnostics, s.errorf(w.offset, "token %s is ignored, it cannot be used in a production", w.text))
+						continue
+					}
+					if declaration.channel != Parser.DEFAULT_CHANNEL {
+						diagnostics = append(diagnostics, s.errorf(w.offset, "token %s is on the %s channel, it cannot be used in a production", w.text, declaration.channel))
+						continue
+					}
+					body = append(body, Parser.ParserSymbol{Id: indexOf(declarations, w.text), Value: w.text, IsTerminal: true})
+				} else if symbol, isNonTerminal := heads[w.text]; isNonTerminal {
+					body = append(body, symbol)
+				} else {
+					diagnostics = append(diagnostics, s.errorf(w.offset, "undefined symbol %s, it is not a token nor the head of a rule", w.text))
+				}
+			}
+
+			productions = append(productions, Parser.ParserProduction{
+				Id:   len(productions) + 1,
+				Head: heads[r.head.text],
+				Body: body,
+				Pos:  s.src.Position(r.head.offset),
+			})
 		}
 	}
-	return false
 
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+
+	return &Parser.ParserDefinition{
+		NonTerminals:   nonTerminals,
+		Terminals:      terminals,
+		Productions:    productions,
+		IgnoredSymbol:  ignored,
+		Channels:       channels,
+		TokenPositions: positions,
+	}, nil
+}
+
+// Index of the first declaration of a token, which is used as its Id.
+func indexOf(declarations []tokenDeclaration, name string) int {
+	for i, declaration := range declarations {
+		if declaration.word.text == name {
+			return i
+		}
+	}
+	return -1
 }
@@ -1,13 +1,20 @@
 package reader
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
 )
 
 func Test_check1(t *testing.T) {
 
-	el, _ := Parse("../../../../examples/medium.par")
+	el, err := Parse("../../../../examples/medium.par")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	fmt.Println(el.NonTerminals)
 
@@ -15,7 +22,10 @@ func Test_check1(t *testing.T) {
 
 func Test_check2(t *testing.T) {
 
-	el, _ := Parse("../../../../examples/simple.par")
+	el, err := Parse("../../../../examples/simple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	fmt.Println(el.Terminals)
 	fmt.Println(el.NonTerminals)
@@ -26,11 +36,113 @@ func Test_check2(t *testing.T) {
 
 func Test_check3(t *testing.T) {
 
-	el, _ := Parse("../../../../examples/exampleprod2.y")
+	el, err := Parse("../../../../examples/superSimple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	fmt.Println(el.Terminals)
 	fmt.Println(el.NonTerminals)
 	fmt.Print("Productions\n")
 	fmt.Println(el.Productions)
 
+	if len(el.Terminals) != 5 || len(el.IgnoredSymbol) != 1 || len(el.Productions) != 5 {
+		t.Errorf("unexpected definition %v", el)
+	}
+}
+
+func Test_layout(t *testing.T) {
+	content := "%token A B /* inline */ C\r\n" +
+		"/* multi\nline */ IGNORE WS\n" +
+		"%% /* separator */\n" +
+		"s: A s /* : | ; */ B\n" +
+		"   | C\n" +
+		"   ;\n" +
+		"t : s A | B ;"
+
+	definition, err := ParseSource(io.NewSource("test.par", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(definition.Terminals) != 3 || definition.IgnoredSymbol[3].Value != "WS" {
+		t.Errorf("unexpected tokens %v %v", definition.Terminals, definition.IgnoredSymbol)
+	}
+	expected := []string{"1: s → A s B", "2: s → C", "3: t → s A", "4: t → B"}
+	for i, production := range definition.Productions {
+		if production.String() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], production.String())
+		}
+	}
+}
+
+func Test_channels(t *testing.T) {
+	content := "%token A B\n" +
+		"IGNORE WS\n" +
+		"%channel comments LINE_COMMENT BLOCK_COMMENT\n" +
+		"%%\n" +
+		"s: A B ;"
+
+	definition, err := ParseSource(io.NewSource("test.par", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int]string{2: "hidden", 3: "comments", 4: "comments"}
+	if fmt.Sprint(definition.Channels) != fmt.Sprint(expected) {
+		t.Errorf("expected channels %v, got %v", expected, definition.Channels)
+	}
+	if len(definition.Terminals) != 2 || len(definition.IgnoredSymbol) != 3 {
+		t.Errorf("unexpected tokens %v %v", definition.Terminals, definition.IgnoredSymbol)
+	}
+}
+
+func Test_diagnostics(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected []string
+	}{
+		{"%token A\n%%\ns: A B ;", []string{"3:6: undefined symbol B"}},
+		{"%token A\n%%\nA: A ;", []string{"3:1: A is declared as a token"}},
+		{"%token A\n%%\ns: A\nt: A ;", []string{"3:5: missing ; at the end of rule s"}},
+		{"%token A\n%%\ns: A", []string{"3:5: missing ; at the end of rule s"}},
+		{"%token A B\n%token A\n%%\ns: A ;", []string{"2:8: token A is declared more than once"}},
+		{"%token A\n%%\ns: ;\nt: A ;", []string{"3:2: rule s has no alternatives"}},
+		{"%token A\n%%\ns: A | ;", []string{"3:2: rule s has an empty alternative, write %empty"}},
+		{"%token A\n%%\ns: A %empty | %empty ;", []string{"3:6: %empty must be alone in its alternative"}},
+		{"%token A\nIGNORE WS\n%%\ns: A WS ;", []string{"4:6: token WS is ignored"}},
+		{"%token A\n%channel comments C\n%%\ns: A C ;", []string{"4:6: token C is on the comments channel"}},
+		{"%token A\n%channel\n%%\ns: A ;", []string{"2:1: %channel declaration without a name"}},
+		{"%token A\n%channel default B\n%%\ns: A ;", []string{"2:10: tokens on the default channel"}},
+		{"%token A\n%%\ns: A | X ;\nt: Y ;", []string{"3:8: undefined symbol X", "4:4: undefined symbol Y"}},
+		{"%token A\n/* open\n%%", []string{"2:1: unterminated comment"}},
+	}
+
+	for _, c := range cases {
+		_, err := ParseSource(io.NewSource("test.par", c.content))
+		if err == nil {
+			t.Errorf("expected errors %v for %q", c.expected, c.content)
+			continue
+		}
+
+		var diagnostics io.Diagnostics
+		var diagnostic *io.Diagnostic
+		if errors.As(err, &diagnostic) {
+			diagnostics = io.Diagnostics{diagnostic}
+		} else if !errors.As(err, &diagnostics) {
+			t.Errorf("expected diagnostics, got %v", err)
+			continue
+		}
+
+		if len(diagnostics) != len(c.expected) {
+			t.Errorf("expected %d errors, got:\n%v", len(c.expected), err)
+			continue
+		}
+		for i, expected := range c.expected {
+			got := fmt.Sprintf("%d:%d: %s", diagnostics[i].Pos.Line, diagnostics[i].Pos.Column, diagnostics[i].Message)
+			if !strings.HasPrefix(got, expected) {
+				t.Errorf("expected %q, got %q", expected, got)
+			}
+		}
+	}
 }
@@ -0,0 +1,125 @@
+package reader
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+)
+
+// This file splits a yapar file into words, the grammar itself lives on reader.go
+
+// Smallest piece of a yapar file: a symbol name, a directive (%token) or
+// one of the punctuation marks ":", "|", ";"
+type word struct {
+	text   string
+	offset int
+}
+
+func (w word) end() int {
+	return w.offset + len(w.text)
+}
+
+func (w word) isPunctuation() bool {
+	return w.text == ":" || w.text == "|" || w.text == ";"
+}
+
+type scanner struct {
+	src *io.Source
+	pos int // Byte offset of the next rune to read
+}
+
+func newScanner(src *io.Source) *scanner {
+	return &scanner{src: src}
+}
+
+func (s *scanner) errorf(offset int, format string, args ...any) *io.Diagnostic {
+	return s.src.Errorf(offset, format, args...)
+}
+
+// Returns the next word of the file, or a word with empty text on EOF.
+func (s *scanner) next() (word, error) {
+	if err := s.skipBlank(true); err != nil {
+		return word{}, err
+	}
+	return s.read(), nil
+}
+
+// Returns all the words left on the current line.
+func (s *scanner) restOfLine() ([]word, error) {
+	words := make([]word, 0)
+	line := s.src.Position(s.pos).Line
+	for {
+		if err := s.skipBlank(false); err != nil {
+			return nil, err
+		}
+		// A comment spanning several lines also ends the line
+		if s.src.Position(s.pos).Line != line {
+			return words, nil
+		}
+		w := s.read()
+		if w.text == "" {
+			return words, nil
+		}
+		words = append(words, w)
+	}
+}
+
+// Checks, without consuming anything, if the next word is ":"
+func (s *scanner) peekColon() bool {
+	saved := s.pos
+	defer func() { s.pos = saved }()
+
+	if err := s.skipBlank(true); err != nil {
+		return false
+	}
+	return strings.HasPrefix(s.src.Content[s.pos:], ":")
+}
+
+// Reads a word starting on the current position. Words are separated by
+// white spaces, comments and punctuation marks.
+func (s *scanner) read() word {
+	start := s.pos
+	content := s.src.Content
+
+	if start < len(content) && strings.ContainsRune(":|;", rune(content[start])) {
+		s.pos++
+		return word{text: content[start:s.pos], offset: start}
+	}
+
+	for s.pos < len(content) {
+		r, size := utf8.DecodeRuneInString(content[s.pos:])
+		if unicode.IsSpace(r) || strings.ContainsRune(":|;", r) || strings.HasPrefix(content[s.pos:], "/*") {
+			break
+		}
+		s.pos += size
+	}
+	return word{text: content[start:s.pos], offset: start}
+}
+
+// Skips white spaces and "/* */" comments. Line breaks are only skipped if
+// multiline is true.
+func (s *scanner) skipBlank(multiline bool) error {
+	content := s.src.Content
+	for s.pos < len(content) {
+		r, size := utf8.DecodeRuneInString(content[s.pos:])
+		if r == '\n' && !multiline {
+			return nil
+		}
+		if unicode.IsSpace(r) {
+			s.pos += size
+			continue
+		}
+		if strings.HasPrefix(content[s.pos:], "/*") {
+			end := strings.Index(content[s.pos+2:], "*/")
+			if end == -1 {
+				return s.errorf(s.pos, "unterminated comment, missing */")
+			}
+			s.pos += end + 4
+			continue
+		}
+		return nil
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package writer
 
 import (
 	parserdef "github.com/DanielRasho/Parser/internal/Parser"
+	predictive "github.com/DanielRasho/Parser/internal/Parser/PredictiveTable"
 	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
 )
 
@@ -9,6 +10,19 @@ import (
 
 // Definition of variable fields withing a template
 type templateLexwrite struct {
+	Gotable          table.GotoTbl
+	TransitTable     table.DeterministicTbl
+	ParserDefinition parserdef.ParserDefinition
+}
+
+// Fields of the LL(1) parser template
+type templateLLwrite struct {
+	PredictiveTable  predictive.PredictiveTbl
+	ParserDefinition parserdef.ParserDefinition
+}
+
+// Fields of the GLR parser template, cells can have more than one movement
+type templateGLRwrite struct {
 	Gotable          table.GotoTbl
 	TransitTable     table.TransitionTbl
 	ParserDefinition parserdef.ParserDefinition
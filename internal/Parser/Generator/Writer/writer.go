@@ -3,26 +3,30 @@ package writer
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"text/template"
 
 	parser "github.com/DanielRasho/Parser/internal/Parser"
+	predictive "github.com/DanielRasho/Parser/internal/Parser/PredictiveTable"
 	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
 )
 
 // Writes a parser.go file in the desired location.
 // Possible errors:
 //   - file paths invalids/not found
-//   - invalid parsing table.
+//   - invalid parsing table, a cell with more than one movement.
 //
 // REMINDER!!!!! DONT LOAD THE ENTIRE FILE ON A STRING, use buffers instead.
-func WriteParserFile(templateFilePath string, outputFilePath string, parserdef *parser.ParserDefinition, transitionTbl *table.GotoTbl, gotoTbl *table.TransitionTbl) error {
+func WriteParserFile(templateFilePath string, outputFilePath string, parserdef *parser.ParserDefinition, transitionTbl *table.TransitionTbl, gotoTbl *table.GotoTbl) error {
+
+	if conflicts := table.Conflicts(*transitionTbl); len(conflicts) > 0 {
+		return fmt.Errorf("invalid parsing table, %d cells have more than one movement", len(conflicts))
+	}
 
 	// Load and parse the template
 	fmt.Println("PRINTING")
-	tmpl, err := template.New("ParserTemplate").Funcs(template.FuncMap{
-		"goLiteral": goLiteral,
-	}).ParseFiles("./template/ParserTemplate.go")
+	tmpl, err := parseTemplate("ParserTemplate", templateFilePath)
 
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
@@ -31,7 +35,7 @@ func WriteParserFile(templateFilePath string, outputFilePath string, parserdef *
 	// Create the data context
 	data := templateLexwrite{
 		ParserDefinition: *parserdef,
-		TransitTable:     *transitionTbl,
+		TransitTable:     table.Deterministic(*transitionTbl),
 		Gotable:          *gotoTbl,
 	}
 
@@ -52,6 +56,81 @@ func WriteParserFile(templateFilePath string, outputFilePath string, parserdef *
 	return nil
 }
 
+// Writes a top-down parser.go file, driven by an LL(1) table, in the desired
+// location. The parser has the same API as the one of WriteParserFile.
+func WriteLLParserFile(templateFilePath string, outputFilePath string, parserdef *parser.ParserDefinition, predictiveTbl *predictive.PredictiveTbl) error {
+
+	tmpl, err := parseTemplate("LLParserTemplate", templateFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := templateLLwrite{
+		ParserDefinition: *parserdef,
+		PredictiveTable:  *predictiveTbl,
+	}
+
+	outFile, err := os.Create(outputFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	err = tmpl.ExecuteTemplate(outFile, "LLParserTemplate", data)
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// Writes a GLR parser.go file in the desired location, following every movement
+// of the cells with a conflict. The parser has the same API as the one of
+// WriteParserFile, plus the parse forest and its filters.
+func WriteGLRParserFile(templateFilePath string, outputFilePath string, parserdef *parser.ParserDefinition, transitionTbl *table.TransitionTbl, gotoTbl *table.GotoTbl) error {
+
+	tmpl, err := parseTemplate("GLRParserTemplate", templateFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := templateGLRwrite{
+		ParserDefinition: *parserdef,
+		TransitTable:     *transitionTbl,
+		Gotable:          *gotoTbl,
+	}
+
+	outFile, err := os.Create(outputFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	err = tmpl.ExecuteTemplate(outFile, "GLRParserTemplate", data)
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// File next to the parser templates with the code they share: channels, the
+// parse tree and syntax errors, defined as "ParserCommon".
+const COMMON_TEMPLATE = "ParserCommonTemplate.go"
+
+// Parses a parser template along with the common one of its folder. Custom
+// templates without it next to them can't include "ParserCommon".
+func parseTemplate(name string, templateFilePath string) (*template.Template, error) {
+	files := []string{templateFilePath}
+	common := filepath.Join(filepath.Dir(templateFilePath), COMMON_TEMPLATE)
+	if _, err := os.Stat(common); err == nil {
+		files = append(files, common)
+	}
+	return template.New(name).Funcs(template.FuncMap{
+		"goLiteral": goLiteral,
+	}).ParseFiles(files...)
+}
+
 func goLiteral(v any) string {
 	raw := fmt.Sprintf("%#v", v)
 
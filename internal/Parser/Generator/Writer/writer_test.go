@@ -1,9 +1,13 @@
 package writer
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	predictive "github.com/DanielRasho/Parser/internal/Parser/PredictiveTable"
 	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
 	automata "github.com/DanielRasho/Parser/internal/Parser/automata"
 )
@@ -23,3 +27,91 @@ func Test_check2(t *testing.T) {
 	WriteParserFile("../../../../template/ParserTemplate.go", "../../../../cmd/compiler/parser.go", parserDef, transitionTbl, gotoTbl)
 
 }
+
+// The SLR parser is written with the template it is given, not the default one.
+func Test_writeCustom(t *testing.T) {
+	parserDef, err := reader.Parse("../../../../examples/superSimple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := table.GetFirst(parserDef)
+	follow := table.GetFollow(parserDef, first)
+	transitionTbl, gotoTbl, _ := table.NewTable(automata.NewAutomata(parserDef, false), first, follow, *parserDef)
+
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "MyParser.go")
+	content := "{{ define \"ParserTemplate\" }}// Custom parser of {{ len .ParserDefinition.Productions }} productions\n{{ end }}"
+	if err := os.WriteFile(custom, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "parser.go")
+	if err := WriteParserFile(custom, output, parserDef, transitionTbl, gotoTbl); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != "// Custom parser of 5 productions\n" {
+		t.Errorf("expected the custom template, got %q", written)
+	}
+}
+
+func Test_writeLL(t *testing.T) {
+	parserDef, err := reader.Parse("../../../../examples/ll1.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := table.GetFirst(parserDef)
+	follow := table.GetFollow(parserDef, first)
+	predictiveTbl, conflicts := predictive.NewTable(parserDef, first, follow)
+	if len(conflicts) > 0 {
+		t.Fatalf("unexpected conflicts %v", conflicts)
+	}
+
+	output := filepath.Join(t.TempDir(), "parser.go")
+	err = WriteLLParserFile("../../../../template/LLParserTemplate.go", output, parserDef, predictiveTbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// program → ε is predicted at the end of the input
+	if !strings.Contains(string(content), `"program": PredictiveTblRow{`) || !strings.Contains(string(content), `"$": 1,`) {
+		t.Errorf("the table is not written on %s", output)
+	}
+}
+
+func Test_writeGLR(t *testing.T) {
+	parserDef, err := reader.Parse("../../../../examples/ambiguous.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := table.GetFirst(parserDef)
+	follow := table.GetFollow(parserDef, first)
+	transitionTbl, gotoTbl, _ := table.NewTable(automata.NewAutomata(parserDef, false), first, follow, *parserDef)
+	if len(table.Conflicts(*transitionTbl)) == 0 {
+		t.Fatal("expected conflicts on the table")
+	}
+
+	// The SLR parser needs one movement per cell
+	if err := WriteParserFile("../../../../template/ParserTemplate.go", filepath.Join(t.TempDir(), "parser.go"), parserDef, transitionTbl, gotoTbl); err == nil {
+		t.Error("expected the SLR parser to fail")
+	}
+
+	output := filepath.Join(t.TempDir(), "parser.go")
+	err = WriteGLRParserFile("../../../../template/GLRParserTemplate.go", output, parserDef, transitionTbl, gotoTbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Shift and reduce on the same cell
+	if !strings.Contains(string(content), "{MovementType: 0, NextRow:") || !strings.Contains(string(content), "{MovementType: 1, NextRow:") {
+		t.Errorf("the table is not written on %s", output)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	parser "github.com/DanielRasho/Parser/internal/Parser"
 	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
 	generator "github.com/DanielRasho/Parser/internal/Parser/Generator/Writer"
+	predictive "github.com/DanielRasho/Parser/internal/Parser/PredictiveTable"
 	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
 	"github.com/DanielRasho/Parser/internal/Parser/automata"
 	"github.com/golang-collections/collections/queue"
@@ -15,7 +16,12 @@ import (
 )
 
 // Given a file to read and a output path, writes a parser definition to the desired path.
-func Compile(filePathparser, filepathtemplate, outputPath string, showLogs bool) error {
+// The template must be the one of the mode, given by mode.Template(), the
+// template of another mode is rejected.
+func Compile(filePathparser, filepathtemplate, outputPath string, showLogs bool, mode Mode) error {
+	if err := mode.CheckTemplate(filepathtemplate); err != nil {
+		return err
+	}
 
 	// Parse Yalex file definition
 	parserDef, err := reader.Parse(filePathparser)
@@ -26,15 +32,47 @@ func Compile(filePathparser, filepathtemplate, outputPath string, showLogs bool)
 	// runtime.Breakpoint()
 	first := table.GetFirst(parserDef)
 	follow := table.GetFollow(parserDef, first)
+	if showLogs {
+		table.PrintSymbolSets("FIRST", first)
+		table.PrintSymbolSets("FOLLOW", follow)
+	}
+
+	if mode == LL1 {
+		return compileLL(parserDef, first, follow, filepathtemplate, outputPath)
+	}
+
+	for _, production := range parserDef.Productions {
+		if production.IsEmpty() {
+			return fmt.Errorf("%s: %s is an ε-production, only the ll1 mode supports them", production.Pos, production.String())
+		}
+	}
 
 	auto := automata.NewAutomata(parserDef, showLogs)
 
-	transitable, gotable, _ := table.NewTable(auto, first, follow, *parserDef)
+	transitable, gotable, err := table.NewTable(auto, first, follow, *parserDef)
+	if err != nil {
+		return err
+	}
 
-	table.PrintMovementTable("TRANSITION TABLE", *transitable)
+	table.PrintTransitionTable("TRANSITION TABLE", *transitable)
 
 	table.PrintMovementTable("GOTO TABLE", *gotable)
 
+	conflicts := table.Conflicts(*transitable)
+	if mode == GLR {
+		// Conflicts are expected, the parser follows every movement
+		fmt.Printf("%d cells with more than one movement\n", len(conflicts))
+		return generator.WriteGLRParserFile(filepathtemplate, outputPath, parserDef, transitable, gotable)
+	}
+
+	if len(conflicts) > 0 {
+		descriptions := make([]string, len(conflicts))
+		for i, conflict := range conflicts {
+			descriptions[i] = conflict.Describe(parserDef)
+		}
+		return fmt.Errorf("the grammar is not SLR, %d conflicts (the glr mode accepts them):\n%s", len(conflicts), strings.Join(descriptions, "\n"))
+	}
+
 	err = generator.WriteParserFile(filepathtemplate, outputPath, parserDef, transitable, gotable)
 	if err != nil {
 		return err
@@ -43,8 +81,25 @@ func Compile(filePathparser, filepathtemplate, outputPath string, showLogs bool)
 	return nil
 }
 
+// Writes a top-down parser, fails listing every conflict when the grammar is not LL(1).
+func compileLL(parserDef *parser.ParserDefinition, first, follow map[string]parser.SymbolSet, filepathtemplate, outputPath string) error {
+	predictiveTbl, conflicts := predictive.NewTable(parserDef, first, follow)
+
+	predictive.PrintPredictiveTable("LL(1) TABLE", *predictiveTbl, parserDef)
+
+	if len(conflicts) > 0 {
+		descriptions := make([]string, len(conflicts))
+		for i, conflict := range conflicts {
+			descriptions[i] = conflict.Describe(parserDef)
+		}
+		return fmt.Errorf("the grammar is not LL(1), %d conflicts:\n%s", len(conflicts), strings.Join(descriptions, "\n"))
+	}
+
+	return generator.WriteLLParserFile(filepathtemplate, outputPath, parserDef, predictiveTbl)
+}
+
 // Funcion de referencia
-func ParseInput(transit table.TransitionTbl, parserdef parser.ParserDefinition, gotable table.GotoTbl, token []Token) *[]Token {
+func ParseInput(transit table.DeterministicTbl, parserdef parser.ParserDefinition, gotable table.GotoTbl, token []Token) *[]Token {
 
 	input := ""
 
@@ -1,5 +1,10 @@
 package generator
 
+import (
+	"fmt"
+	"path/filepath"
+)
+
 type Symbol = string
 
 type Token struct {
@@ -7,3 +12,62 @@ type Token struct {
 	TokenID int    // Token Id (defined by the user above)
 	Offset  int    // No of bytes from the start of the file to the current lexeme
 }
+
+// Kind of parser written by Compile.
+type Mode int
+
+const (
+	SLR Mode = iota // Bottom-up, shifts and reduces over the LR(0) automata
+	LL1             // Top-down, predicts the production to expand from the next token
+	GLR             // Bottom-up like SLR, following every movement of a conflict
+)
+
+// Returns the parsing mode for its name on the command line, "slr", "ll1" or "glr".
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "slr":
+		return SLR, nil
+	case "ll1":
+		return LL1, nil
+	case "glr":
+		return GLR, nil
+	}
+	return SLR, fmt.Errorf("unknown parsing mode %q, expected slr, ll1 or glr", name)
+}
+
+// File, within the template folder, every mode is written with.
+var modeTemplates = map[Mode]string{
+	SLR: "ParserTemplate.go",
+	LL1: "LLParserTemplate.go",
+	GLR: "GLRParserTemplate.go",
+}
+
+// Returns the path of the template the mode is written with, relative to the
+// root of the repository.
+func (m Mode) Template() string {
+	return "./template/" + modeTemplates[m]
+}
+
+// Fails when the template is the one of another mode, the parser written would
+// use tables it doesn't know how to read. Templates with other names are
+// assumed to be made for the mode.
+func (m Mode) CheckTemplate(path string) error {
+	name := filepath.Base(path)
+	for mode, template := range modeTemplates {
+		if name == template && mode != m {
+			return fmt.Errorf("%s is the template of the %s mode, the %s mode is written with %s", path, mode, m, modeTemplates[m])
+		}
+	}
+	return nil
+}
+
+// Name of the mode on the command line.
+func (m Mode) String() string {
+	switch m {
+	case LL1:
+		return "ll1"
+	case GLR:
+		return "glr"
+	}
+	return "slr"
+}
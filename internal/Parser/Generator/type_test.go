@@ -0,0 +1,20 @@
+package generator
+
+import "testing"
+
+func Test_checkTemplate(t *testing.T) {
+	for _, mode := range []Mode{SLR, LL1, GLR} {
+		if err := mode.CheckTemplate(mode.Template()); err != nil {
+			t.Errorf("%s: %s", mode, err)
+		}
+		if err := mode.CheckTemplate("custom/MyTemplate.go"); err != nil {
+			t.Errorf("%s: templates of no mode should be accepted, got %s", mode, err)
+		}
+	}
+
+	err := GLR.CheckTemplate("template/ParserTemplate.go")
+	expected := "template/ParserTemplate.go is the template of the slr mode, the glr mode is written with GLRParserTemplate.go"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,105 @@
+package predictivetable
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	transitiontable "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Builds the LL(1) table of a grammar. A production A → α is predicted by the
+// terminals of FIRST(α), and when α is nullable also by the ones of FOLLOW(A).
+// Cells predicted by more than one production are returned as conflicts, sorted
+// by non terminal and terminal.
+func NewTable(def *parser.ParserDefinition, first map[string]parser.SymbolSet, follow map[string]parser.SymbolSet) (*PredictiveTbl, []Conflict) {
+
+	predictions := make(map[string]map[string][]int)
+	for _, nonTerminal := range def.NonTerminals {
+		predictions[nonTerminal.Value] = make(map[string][]int)
+	}
+
+	for i, prod := range def.Productions {
+		head := prod.Head.Value
+		predicted := transitiontable.GetFirstOfSequence(prod.Body, first)
+		if _, nullable := predicted[parser.EPSILON]; nullable {
+			delete(predicted, parser.EPSILON)
+			for terminal := range follow[head] {
+				predicted[terminal] = struct{}{}
+			}
+		}
+		for terminal := range predicted {
+			predictions[head][terminal.Value] = append(predictions[head][terminal.Value], i)
+		}
+	}
+
+	tbl := PredictiveTbl{}
+	conflicts := make([]Conflict, 0)
+	for _, nonTerminal := range def.NonTerminals {
+		row := PredictiveTblRow{}
+		for _, terminal := range sortedKeys(predictions[nonTerminal.Value]) {
+			productions := predictions[nonTerminal.Value][terminal]
+			row[terminal] = productions[0]
+			if len(productions) > 1 {
+				conflicts = append(conflicts, Conflict{NonTerminal: nonTerminal.Value, Lookahead: terminal, Productions: productions})
+			}
+		}
+		tbl[nonTerminal.Value] = row
+	}
+
+	return &tbl, conflicts
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Prints the table with a row per non terminal, each cell shows the body of the
+// production predicted.
+func PrintPredictiveTable(title string, tbl PredictiveTbl, def *parser.ParserDefinition) {
+	columnSet := make(map[string]struct{})
+	for _, row := range tbl {
+		for col := range row {
+			columnSet[col] = struct{}{}
+		}
+	}
+	columns := sortedKeys(columnSet)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header(append([]string{"Non terminal"}, columns...))
+
+	for _, nonTerminal := range def.NonTerminals {
+		row := []string{nonTerminal.Value}
+		for _, col := range columns {
+			if index, ok := tbl[nonTerminal.Value][col]; ok {
+				row = append(row, bodyToString(def.Productions[index]))
+			} else {
+				row = append(row, "")
+			}
+		}
+		table.Append(row)
+	}
+
+	fmt.Println("=== " + title + " ===")
+	table.Render()
+}
+
+// Helper to write the body of a production, ε for empty ones.
+func bodyToString(prod parser.ParserProduction) string {
+	if prod.IsEmpty() {
+		return parser.EPSILON.Value
+	}
+	symbols := make([]string, len(prod.Body))
+	for i, symbol := range prod.Body {
+		symbols[i] = symbol.Value
+	}
+	return strings.Join(symbols, " ")
+}
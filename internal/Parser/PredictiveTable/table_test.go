@@ -0,0 +1,82 @@
+package predictivetable
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+func newTable(t *testing.T, path string) (*parser.ParserDefinition, *PredictiveTbl, []Conflict) {
+	def, err := reader.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := table.GetFirst(def)
+	follow := table.GetFollow(def, first)
+	tbl, conflicts := NewTable(def, first, follow)
+	return def, tbl, conflicts
+}
+
+func Test_ll1(t *testing.T) {
+	def, tbl, conflicts := newTable(t, "../../../examples/ll1.par")
+	PrintPredictiveTable("LL(1) TABLE", *tbl, def)
+	if len(conflicts) > 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+
+	// Empty productions are predicted by the FOLLOW set of their head
+	cases := []struct {
+		nonTerminal string
+		lookahead   string
+		body        string
+	}{
+		{"program", "LET", "statement program"},
+		{"program", "$", "ε"},
+		{"statement", "ID", "expression SEMICOLON"},
+		{"expression_tail", "MINUS", "MINUS term expression_tail"},
+		{"expression_tail", "RPAREN", "ε"},
+		{"expression_tail", "SEMICOLON", "ε"},
+		{"term_tail", "PLUS", "ε"},
+		{"factor", "LPAREN", "LPAREN expression RPAREN"},
+	}
+	for _, c := range cases {
+		index, ok := (*tbl)[c.nonTerminal][c.lookahead]
+		if !ok || bodyToString(def.Productions[index]) != c.body {
+			t.Errorf("expected %s with %s to predict %s, got %v", c.nonTerminal, c.lookahead, c.body, (*tbl)[c.nonTerminal])
+		}
+	}
+	if _, ok := (*tbl)["expression_tail"]["$"]; ok {
+		t.Errorf("expression_tail is never at the end of the input")
+	}
+}
+
+func Test_conflicts(t *testing.T) {
+	// Left recursion is never LL(1)
+	def, _, conflicts := newTable(t, "../../../examples/medium.par")
+	found := false
+	for _, c := range conflicts {
+		fmt.Println(c.Describe(def))
+		if c.NonTerminal == "expression" && c.Lookahead == "ID" {
+			found = true
+			if !strings.Contains(c.Describe(def), "expression → expression PLUS term") {
+				t.Errorf("expected the left recursive production on %s", c.Describe(def))
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a conflict on expression with ID, got %v", conflicts)
+	}
+
+	// E → T + E | T and T → int * T | int share their prefix
+	def, tbl, conflicts := newTable(t, "../../../examples/superSimple.par")
+	if fmt.Sprint(conflicts) != "[{E ( [0 1]} {E int [0 1]} {T int [2 3]}]" {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+	if (*tbl)["E"]["int"] != 0 {
+		t.Errorf("expected the first production to be kept, got %s", def.Productions[(*tbl)["E"]["int"]].String())
+	}
+}
@@ -0,0 +1,40 @@
+package predictivetable
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+)
+
+// LL(1) parsing table. For each non terminal on top of the stack and the next
+// terminal of the input, the index (on ParserDefinition.Productions) of the
+// production to expand it with. "$" stands for the end of the input.
+//
+//	{"expression": {"ID": 4, "NUMBER": 4}, "expression_tail": {"PLUS": 5, "$": 7}}
+type PredictiveTbl = map[string]PredictiveTblRow
+type PredictiveTblRow = map[string]int
+
+// More than one production of a non terminal predicted by the same terminal,
+// the grammar is not LL(1). The table keeps the first one defined.
+type Conflict struct {
+	NonTerminal string
+	Lookahead   string
+	// Indexes on ParserDefinition.Productions, in the order they were defined
+	Productions []int
+}
+
+// Describes the conflict with the productions involved and where they are defined. Ex:
+//
+//	expression with ID predicts 2 productions:
+//		examples/medium.par:44:1  7: expression → expression PLUS term
+//		examples/medium.par:44:1  12: expression → term
+func (c Conflict) Describe(definition *parser.ParserDefinition) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s with %s predicts %d productions:", c.NonTerminal, c.Lookahead, len(c.Productions)))
+	for _, index := range c.Productions {
+		production := definition.Productions[index]
+		sb.WriteString(fmt.Sprintf("\n\t%s  %s", production.Pos, production.String()))
+	}
+	return sb.String()
+}
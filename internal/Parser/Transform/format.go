@@ -0,0 +1,87 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+)
+
+// Format writes the grammar back in the yapar syntax, with a comment listing the
+// helpers and the non terminal each one comes from. Tokens are declared in the
+// same order, so their Ids still match the lexer.
+func (r *Result) Format() string {
+	var sb strings.Builder
+	def := r.Definition
+
+	if len(r.Helpers) > 0 {
+		sb.WriteString("/* Helper non terminals:\n")
+		for _, helper := range r.Helpers {
+			sb.WriteString(fmt.Sprintf("\t%s\tfrom %s (%s)\n", helper.Name, helper.Original, helper.Reason))
+		}
+		sb.WriteString("*/\n\n")
+	}
+
+	// Consecutive tokens on the same channel share a line
+	declarations := make([]parser.ParserSymbol, 0, len(def.Terminals)+len(def.IgnoredSymbol))
+	declarations = append(declarations, def.Terminals...)
+	for _, symbol := range def.IgnoredSymbol {
+		declarations = append(declarations, symbol)
+	}
+	sort.Slice(declarations, func(i, j int) bool { return declarations[i].Id < declarations[j].Id })
+
+	lastChannel := ""
+	for i, symbol := range declarations {
+		channel, ok := def.Channels[symbol.Id]
+		if !ok {
+			channel = parser.DEFAULT_CHANNEL
+		}
+		if i == 0 || channel != lastChannel {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			switch channel {
+			case parser.DEFAULT_CHANNEL:
+				sb.WriteString("%token")
+			case parser.HIDDEN_CHANNEL:
+				sb.WriteString("IGNORE")
+			default:
+				sb.WriteString("%channel " + channel)
+			}
+		}
+		sb.WriteString(" " + symbol.Value)
+		lastChannel = channel
+	}
+	sb.WriteString("\n\n%%\n")
+
+	for _, head := range def.NonTerminals {
+		first := true
+		for _, production := range def.Productions {
+			if production.Head.Value != head.Value {
+				continue
+			}
+			if first {
+				sb.WriteString("\n" + head.Value + ":\n    ")
+				first = false
+			} else {
+				sb.WriteString("\n  | ")
+			}
+			if production.IsEmpty() {
+				sb.WriteString(reader.EMPTY_KEYWORD)
+			}
+			for i, symbol := range production.Body {
+				if i > 0 {
+					sb.WriteString(" ")
+				}
+				sb.WriteString(symbol.Value)
+			}
+		}
+		if !first {
+			sb.WriteString("\n;\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,358 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+// ForTopDown removes the left recursion of a grammar and then left factors it,
+// the usual steps before building an LL(1) table. The helpers of both are returned.
+func ForTopDown(def *parser.ParserDefinition) (*Result, error) {
+	withoutRecursion, err := RemoveLeftRecursion(def)
+	if err != nil {
+		return nil, err
+	}
+	factored := LeftFactor(withoutRecursion.Definition)
+	factored.Helpers = append(withoutRecursion.Helpers, factored.Helpers...)
+	return factored, nil
+}
+
+// RemoveLeftRecursion rewrites a grammar so no non terminal derives a sentential
+// form starting with itself. Non terminals are taken in order, the productions
+// starting with a previous one that can lead back to it are expanded with its
+// productions (turning the indirect recursion into direct) and then the direct
+// recursion is replaced by a helper:
+//
+//	A → A α | β  becomes  A → β A_tail, A_tail → α A_tail | ε
+//
+// Cycles like A → A are dropped. It fails when a non terminal has only left
+// recursive productions, or when the recursion goes through nullable symbols
+// (A → B A x, B → ε), which expanding can't remove.
+func RemoveLeftRecursion(def *parser.ParserDefinition) (*Result, error) {
+	g := newGrammar(def)
+
+	order := append([]parser.ParserSymbol{}, g.nonTerminals...)
+	for i, head := range order {
+		for _, previous := range order[:i] {
+			if g.startsWith(previous.Value, head.Value) {
+				g.expand(head.Value, previous.Value)
+			}
+		}
+		if err := g.removeDirectRecursion(head); err != nil {
+			return nil, err
+		}
+	}
+
+	result := g.result()
+	if err := checkLeftRecursion(result.Definition); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LeftFactor rewrites a grammar so no two productions of a non terminal start
+// with the same symbol, their common prefix is kept and what follows goes to a helper:
+//
+//	A → α β | α γ  becomes  A → α A_rest, A_rest → β | γ
+func LeftFactor(def *parser.ParserDefinition) *Result {
+	g := newGrammar(def)
+	// Helpers are inserted after the non terminal they come from, so they are factored too
+	for i := 0; i < len(g.nonTerminals); i++ {
+		for g.factor(g.nonTerminals[i]) {
+		}
+	}
+	return g.result()
+}
+
+func newGrammar(def *parser.ParserDefinition) *grammar {
+	g := &grammar{
+		nonTerminals: append([]parser.ParserSymbol{}, def.NonTerminals...),
+		productions:  make(map[string][]parser.ParserProduction),
+		taken:        make(map[string]struct{}),
+		original:     def,
+	}
+	for _, symbol := range def.NonTerminals {
+		g.taken[symbol.Value] = struct{}{}
+	}
+	for _, symbol := range def.Terminals {
+		g.taken[symbol.Value] = struct{}{}
+	}
+	for _, symbol := range def.IgnoredSymbol {
+		g.taken[symbol.Value] = struct{}{}
+	}
+	for _, production := range def.Productions {
+		g.productions[production.Head.Value] = appendUnique(g.productions[production.Head.Value], production)
+	}
+	return g
+}
+
+// Replaces the productions of head starting with target by one for each
+// production of target. Ex: with B → b | c, A → B x becomes A → b x | c x
+func (g *grammar) expand(head, target string) {
+	expanded := make([]parser.ParserProduction, 0)
+	for _, production := range g.productions[head] {
+		if production.IsEmpty() || production.Body[0].Value != target {
+			expanded = appendUnique(expanded, production)
+			continue
+		}
+		for _, replacement := range g.productions[target] {
+			body := append(append([]parser.ParserSymbol{}, replacement.Body...), production.Body[1:]...)
+			expanded = appendUnique(expanded, parser.ParserProduction{Head: production.Head, Body: body, Pos: production.Pos})
+		}
+	}
+	g.productions[head] = expanded
+}
+
+// Checks if a sentential form starting with target can be derived from head,
+// following the first symbol of the productions.
+func (g *grammar) startsWith(head, target string) bool {
+	visited := map[string]bool{}
+	pending := []string{head}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		for _, production := range g.productions[current] {
+			if production.IsEmpty() || production.Body[0].IsTerminal {
+				continue
+			}
+			if production.Body[0].Value == target {
+				return true
+			}
+			pending = append(pending, production.Body[0].Value)
+		}
+	}
+	return false
+}
+
+func (g *grammar) removeDirectRecursion(head parser.ParserSymbol) error {
+	recursive := make([]parser.ParserProduction, 0)
+	others := make([]parser.ParserProduction, 0)
+	for _, production := range g.productions[head.Value] {
+		if production.IsEmpty() || production.Body[0].Value != head.Value {
+			others = append(others, production)
+		} else if len(production.Body) > 1 { // A → A derives nothing new
+			recursive = append(recursive, production)
+		}
+	}
+	if len(recursive) == 0 {
+		g.productions[head.Value] = others
+		return nil
+	}
+	if len(others) == 0 {
+		return fmt.Errorf("%s: every production of %s is left recursive, it never derives a sentence", recursive[0].Pos, head.Value)
+	}
+
+	tail := g.addHelper(head, LEFT_RECURSION)
+	g.productions[head.Value] = make([]parser.ParserProduction, 0, len(others))
+	for _, production := range others {
+		body := append(append([]parser.ParserSymbol{}, production.Body...), tail)
+		g.productions[head.Value] = append(g.productions[head.Value], parser.ParserProduction{Head: head, Body: body, Pos: production.Pos})
+	}
+	for _, production := range recursive {
+		body := append(append([]parser.ParserSymbol{}, production.Body[1:]...), tail)
+		g.productions[tail.Value] = appendUnique(g.productions[tail.Value], parser.ParserProduction{Head: tail, Body: body, Pos: production.Pos})
+	}
+	g.productions[tail.Value] = append(g.productions[tail.Value], parser.ParserProduction{Head: tail, Body: []parser.ParserSymbol{}, Pos: recursive[0].Pos})
+	return nil
+}
+
+// Factors the first group of productions of head sharing their first symbol,
+// false if there is none.
+func (g *grammar) factor(head parser.ParserSymbol) bool {
+	productions := g.productions[head.Value]
+	for i, production := range productions {
+		if production.IsEmpty() {
+			continue
+		}
+		group := []int{i}
+		for j := i + 1; j < len(productions); j++ {
+			if !productions[j].IsEmpty() && productions[j].Body[0].Value == production.Body[0].Value {
+				group = append(group, j)
+			}
+		}
+		if len(group) < 2 {
+			continue
+		}
+
+		prefix := production.Body
+		for _, j := range group[1:] {
+			prefix = commonPrefix(prefix, productions[j].Body)
+		}
+
+		rest := g.addHelper(head, LEFT_FACTORING)
+		body := append(append([]parser.ParserSymbol{}, prefix...), rest)
+		factored := make([]parser.ParserProduction, 0, len(productions)-len(group)+1)
+		for j, other := range productions {
+			if j == i {
+				factored = append(factored, parser.ParserProduction{Head: head, Body: body, Pos: production.Pos})
+			} else if !contains(group, j) {
+				factored = append(factored, other)
+			}
+		}
+		g.productions[head.Value] = factored
+
+		for _, j := range group {
+			suffix := append([]parser.ParserSymbol{}, productions[j].Body[len(prefix):]...)
+			g.productions[rest.Value] = appendUnique(g.productions[rest.Value], parser.ParserProduction{Head: rest, Body: suffix, Pos: productions[j].Pos})
+		}
+		return true
+	}
+	return false
+}
+
+// Adds a non terminal after the one it helps and its previous helpers. It is
+// named after the non terminal of the original grammar. Ex: expression_tail, expression_rest2
+func (g *grammar) addHelper(of parser.ParserSymbol, reason Transformation) parser.ParserSymbol {
+	original := of.Value
+	for _, helper := range g.helpers {
+		if helper.Name == of.Value {
+			original = helper.Original
+		}
+	}
+
+	suffix := "_tail"
+	if reason == LEFT_FACTORING {
+		suffix = "_rest"
+	}
+	name := original + suffix
+	for n := 2; ; n++ {
+		if _, taken := g.taken[name]; !taken {
+			break
+		}
+		name = original + suffix + strconv.Itoa(n)
+	}
+	g.taken[name] = struct{}{}
+
+	helper := parser.ParserSymbol{Id: parser.NON_TERMINAL_ID, Value: name}
+	position := 0
+	for i, symbol := range g.nonTerminals {
+		if symbol.Value == original || g.helps(symbol.Value, original) {
+			position = i + 1
+		}
+	}
+	g.nonTerminals = append(g.nonTerminals[:position], append([]parser.ParserSymbol{helper}, g.nonTerminals[position:]...)...)
+	g.helpers = append(g.helpers, Helper{Name: name, Original: original, Reason: reason})
+	return helper
+}
+
+// Checks if a non terminal is a helper of one of the original grammar.
+func (g *grammar) helps(name, original string) bool {
+	for _, helper := range g.helpers {
+		if helper.Name == name {
+			return helper.Original == original
+		}
+	}
+	return false
+}
+
+// Builds the final definition, productions are grouped by non terminal and
+// numbered again. Tokens are kept as they were.
+func (g *grammar) result() *Result {
+	productions := make([]parser.ParserProduction, 0)
+	for _, head := range g.nonTerminals {
+		for _, production := range g.productions[head.Value] {
+			production.Id = len(productions) + 1
+			productions = append(productions, production)
+		}
+	}
+
+	return &Result{
+		Definition: &parser.ParserDefinition{
+			NonTerminals:   g.nonTerminals,
+			Terminals:      g.original.Terminals,
+			Productions:    productions,
+			IgnoredSymbol:  g.original.IgnoredSymbol,
+			Channels:       g.original.Channels,
+			TokenPositions: g.original.TokenPositions,
+		},
+		Helpers: g.helpers,
+	}
+}
+
+// Fails when a non terminal can still derive a sentential form starting with
+// itself, going through nullable symbols at the start of its productions.
+func checkLeftRecursion(def *parser.ParserDefinition) error {
+	first := table.GetFirst(def)
+	nullable := func(symbol parser.ParserSymbol) bool {
+		_, ok := first[symbol.Value][parser.EPSILON]
+		return !symbol.IsTerminal && ok
+	}
+
+	// Non terminals that can be on the left of each production
+	corners := make(map[string][]string)
+	for _, production := range def.Productions {
+		for _, symbol := range production.Body {
+			if symbol.IsTerminal {
+				break
+			}
+			corners[production.Head.Value] = append(corners[production.Head.Value], symbol.Value)
+			if !nullable(symbol) {
+				break
+			}
+		}
+	}
+
+	for _, start := range def.NonTerminals {
+		visited := map[string]bool{}
+		pending := append([]string{}, corners[start.Value]...)
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if current == start.Value {
+				return fmt.Errorf("%s is left recursive through nullable symbols, which can't be removed", start.Value)
+			}
+			if visited[current] {
+				continue
+			}
+			visited[current] = true
+			pending = append(pending, corners[current]...)
+		}
+	}
+	return nil
+}
+
+// Adds a production unless there is one with the same body.
+func appendUnique(productions []parser.ParserProduction, production parser.ParserProduction) []parser.ParserProduction {
+	for _, other := range productions {
+		if sameBody(other.Body, production.Body) {
+			return productions
+		}
+	}
+	return append(productions, production)
+}
+
+func sameBody(a, b []parser.ParserSymbol) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefix(a, b []parser.ParserSymbol) []parser.ParserSymbol {
+	n := 0
+	for n < len(a) && n < len(b) && a[n].Value == b[n].Value {
+		n++
+	}
+	return a[:n]
+}
+
+func contains(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
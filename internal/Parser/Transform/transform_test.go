@@ -0,0 +1,230 @@
+package transform
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
+	parser "github.com/DanielRasho/Parser/internal/Parser"
+	fuzzer "github.com/DanielRasho/Parser/internal/Parser/Fuzzer"
+	reader "github.com/DanielRasho/Parser/internal/Parser/Generator/Reader"
+	predictive "github.com/DanielRasho/Parser/internal/Parser/PredictiveTable"
+	table "github.com/DanielRasho/Parser/internal/Parser/TransitionTable"
+)
+
+func parse(t *testing.T, content string) *parser.ParserDefinition {
+	def, err := reader.ParseSource(io.NewSource("test.par", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return def
+}
+
+// Writes the productions one per line. Ex: "A → b A_tail"
+func productions(def *parser.ParserDefinition) string {
+	lines := make([]string, len(def.Productions))
+	for i, production := range def.Productions {
+		lines[i] = strings.SplitN(production.String(), ": ", 2)[1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func Test_removeLeftRecursion(t *testing.T) {
+	// The indirect recursion S → A a → S d a goes through A
+	def := parse(t, "%token a b c d e\n%%\nS: A a | b ;\nA: A c | S d | e ;")
+	result, err := RemoveLeftRecursion(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"S → A a",
+		"S → b",
+		"A → b d A_tail",
+		"A → e A_tail",
+		"A_tail → c A_tail",
+		"A_tail → a d A_tail",
+		"A_tail → ε",
+	}, "\n")
+	if got := productions(result.Definition); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if fmt.Sprint(result.Helpers) != "[{A_tail A left recursion}]" {
+		t.Errorf("unexpected helpers %v", result.Helpers)
+	}
+
+	// Non terminals that don't lead back are not expanded
+	def = parse(t, "%token x y\n%%\nlist: list item | item ;\nitem: x | y ;")
+	result, err = RemoveLeftRecursion(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := productions(result.Definition); got != "list → item list_tail\nlist_tail → item list_tail\nlist_tail → ε\nitem → x\nitem → y" {
+		t.Errorf("unexpected productions:\n%s", got)
+	}
+
+	errors := []struct {
+		content  string
+		expected string
+	}{
+		{"%token a\n%%\ns: s a ;", "every production of s is left recursive"},
+		{"%token a b\n%%\ns: n s a | b ;\nn: %empty | a ;", "s is left recursive through nullable symbols"},
+	}
+	for _, c := range errors {
+		_, err := RemoveLeftRecursion(parse(t, c.content))
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("expected %q, got %v", c.expected, err)
+		}
+	}
+}
+
+func Test_leftFactor(t *testing.T) {
+	// The helpers of a non terminal are factored too
+	def := parse(t, "%token a b c d\n%%\ns: a b c | a b d | a b | d ;")
+	result := LeftFactor(def)
+	expected := strings.Join([]string{
+		"s → a b s_rest",
+		"s → d",
+		"s_rest → c",
+		"s_rest → d",
+		"s_rest → ε",
+	}, "\n")
+	if got := productions(result.Definition); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	def = parse(t, "%token a b c\n%%\ns: a b | a c b | a c c ;")
+	result = LeftFactor(def)
+	expected = strings.Join([]string{
+		"s → a s_rest",
+		"s_rest → b",
+		"s_rest → c s_rest2",
+		"s_rest2 → b",
+		"s_rest2 → c",
+	}, "\n")
+	if got := productions(result.Definition); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if fmt.Sprint(result.Helpers) != "[{s_rest s left factoring} {s_rest2 s left factoring}]" {
+		t.Errorf("unexpected helpers %v", result.Helpers)
+	}
+}
+
+func Test_format(t *testing.T) {
+	def, err := reader.Parse("../../../examples/superSimple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ForTopDown(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `/* Helper non terminals:
+	E_rest	from E (left factoring)
+	T_rest	from T (left factoring)
+*/
+
+%token int + * ( )
+IGNORE WS
+
+%%
+
+E:
+    T E_rest
+;
+
+E_rest:
+    + E
+  | %empty
+;
+
+T:
+    int T_rest
+  | ( E )
+;
+
+T_rest:
+    * T
+  | %empty
+;
+`
+	if got := result.Format(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+// Recognizes a list of terminals with an LL(1) table.
+func recognize(def *parser.ParserDefinition, tbl predictive.PredictiveTbl, input []string) bool {
+	input = append(input, "$")
+	stack := []parser.ParserSymbol{def.Productions[0].Head}
+	next := 0
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top.IsTerminal {
+			if top.Value != input[next] {
+				return false
+			}
+			next++
+			continue
+		}
+		index, ok := tbl[top.Value][input[next]]
+		if !ok {
+			return false
+		}
+		body := def.Productions[index].Body
+		for i := len(body) - 1; i >= 0; i-- {
+			stack = append(stack, body[i])
+		}
+	}
+	return input[next] == "$"
+}
+
+func Test_forTopDown(t *testing.T) {
+	for _, example := range []string{"superSimple", "simple", "medium", "hard"} {
+		def, err := reader.Parse("../../../examples/" + example + ".par")
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := ForTopDown(def)
+		if err != nil {
+			t.Fatalf("%s: %s", example, err)
+		}
+
+		// The written grammar is read back the same
+		again, err := reader.ParseSource(io.NewSource(example+".par", result.Format()))
+		if err != nil {
+			t.Fatalf("%s: %s", example, err)
+		}
+		if productions(again) != productions(result.Definition) || fmt.Sprint(again.Terminals) != fmt.Sprint(def.Terminals) {
+			t.Errorf("%s: the grammar changes when written", example)
+		}
+
+		first := table.GetFirst(result.Definition)
+		follow := table.GetFollow(result.Definition, first)
+		tbl, conflicts := predictive.NewTable(result.Definition, first, follow)
+		fmt.Printf("%s: %d helpers, %d LL(1) conflicts left\n", example, len(result.Helpers), len(conflicts))
+
+		// Medium and hard have statements starting with an expression or an
+		// assignment, both start with ID
+		if example == "superSimple" || example == "simple" {
+			if len(conflicts) > 0 {
+				t.Errorf("%s: unexpected conflicts %v", example, conflicts)
+				continue
+			}
+			f, err := fuzzer.NewFuzzer(def)
+			if err != nil {
+				t.Fatal(err)
+			}
+			random := rand.New(rand.NewSource(47))
+			for i := 0; i < 200; i++ {
+				sentence := strings.Fields(f.Generate(fuzzer.Options{MaxDepth: 8, Random: random}).Text(" "))
+				if !recognize(result.Definition, *tbl, sentence) {
+					t.Errorf("%s: %q is not derived by the new grammar", example, strings.Join(sentence, " "))
+					break
+				}
+			}
+		}
+	}
+}
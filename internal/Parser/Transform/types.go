@@ -0,0 +1,41 @@
+package transform
+
+import parser "github.com/DanielRasho/Parser/internal/Parser"
+
+// Why a helper non terminal was added
+type Transformation = string
+
+const (
+	// A → A α | β  becomes  A → β A_tail, A_tail → α A_tail | ε
+	LEFT_RECURSION Transformation = "left recursion"
+	// A → α β | α γ  becomes  A → α A_rest, A_rest → β | γ
+	LEFT_FACTORING Transformation = "left factoring"
+)
+
+// Non terminal added by a transformation, it derives part of what a non
+// terminal of the original grammar did.
+//
+//	{Name: "expression_tail", Original: "expression", Reason: LEFT_RECURSION}
+type Helper struct {
+	Name     string
+	Original string
+	Reason   Transformation
+}
+
+// A rewritten grammar. It derives the same sentences as the original one, the
+// parse trees differ on the helpers.
+type Result struct {
+	Definition *parser.ParserDefinition
+	// In the order they were added
+	Helpers []Helper
+}
+
+// Grammar being rewritten, the productions of each non terminal in order.
+type grammar struct {
+	nonTerminals []parser.ParserSymbol
+	productions  map[string][]parser.ParserProduction
+	helpers      []Helper
+	// Names of every symbol, so helpers don't clash with them
+	taken    map[string]struct{}
+	original *parser.ParserDefinition
+}
@@ -2,74 +2,103 @@ package transitiontable
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	parser "github.com/DanielRasho/Parser/internal/Parser"
 	automata "github.com/DanielRasho/Parser/internal/Parser/automata"
 )
 
+// Builds the SLR tables from the LR(0) automata. Every movement possible is kept:
+// a shift for each terminal with a transition, a reduction of each production
+// scanned completely for the terminals on the FOLLOW set of its head, and accept
+// on "$" at the state that scanned the start symbol. Cells are sorted, shifts
+// first and reductions by production.
 func NewTable(a *automata.Automata, first map[string]parser.SymbolSet, follow map[string]parser.SymbolSet, Parserdefinition parser.ParserDefinition) (*TransitionTbl, *GotoTbl, error) {
 
 	gototable := GotoTbl{}
 	transit := TransitionTbl{}
-	// Leemos para el go to
-	for i := 0; i < len(a.States); i++ {
+	for _, state := range a.States {
 
-		value := strconv.Itoa(i)
-		gototable[value] = GotoTblRow{}
-		transit[value] = TransitionTblRow{}
-		for e := range a.States[i].Transitions {
+		idnumber := strconv.Itoa(state.Id)
+		gototable[idnumber] = GotoTblRow{}
+		transit[idnumber] = TransitionTblRow{}
+		for e, next := range state.Transitions {
 
 			//Identifica si es no terminal para agregarlo a la tabla de goto
 			if CheckNonTerminal(e.Value, Parserdefinition) {
-
-				idnumber := strconv.Itoa(a.States[i].Id)
-				gototable[idnumber][e.Value] = Movement{MovementType: 2, NextRow: a.States[i].Transitions[e].Id}
-				// fmt.Println(transit)
+				gototable[idnumber][e.Value] = Movement{MovementType: GOTO, NextRow: next.Id}
+				continue
 			}
 			// Si es un terminal entonces solo se agrega los shift
-			if !CheckNonTerminal(e.Value, Parserdefinition) {
-				idnumber := strconv.Itoa(a.States[i].Id)
-				transit[idnumber][e.Value] = Movement{MovementType: 0, NextRow: a.States[i].Transitions[e].Id}
-			}
-
+			transit[idnumber][e.Value] = append(transit[idnumber][e.Value], Movement{MovementType: SHIFT, NextRow: next.Id})
 		}
 
-		if a.States[i].IsAccepted {
-			toshift := a.States[i].Productions[0]
-			for e := 0; e < len(a.States[i].Productions); e++ {
-
-				if len(a.States[i].Productions[e].Body) < len(toshift.Body) {
-					toshift = a.States[i].Productions[e]
-				}
+		if state.IsFinal {
+			transit[idnumber]["$"] = append(transit[idnumber]["$"], Movement{MovementType: ACCEPT, NextRow: -1})
+		}
 
+		for _, production := range state.Reductions {
+			value := Getindexprodcutions(production, Parserdefinition)
+			if value < 0 {
+				return nil, nil, fmt.Errorf("state %s reduces %s, which is not on the grammar", idnumber, production.String())
 			}
+			for sym := range follow[production.Head.Value] {
+				transit[idnumber][sym.Value] = append(transit[idnumber][sym.Value], Movement{MovementType: REDUCE, NextRow: value})
+			}
+		}
 
-			if a.States[i].Id == 1 {
-				idnumber := strconv.Itoa(a.States[i].Id)
-				transit[idnumber]["$"] = Movement{MovementType: 3, NextRow: -1}
-
-			} else {
-
-				toshift = *GetOneProductionWithNoTransitions(a.States[i])
-				// fmt.Println(Parserdefinition.Productions[Getindexprodcutions(toshift, Parserdefinition)])
-				fset := follow[Parserdefinition.Productions[Getindexprodcutions(toshift, Parserdefinition)].Head.Value]
-				for sym := range fset {
-					idnumber := strconv.Itoa(a.States[i].Id)
-					value := Getindexprodcutions(toshift, Parserdefinition)
-					transit[idnumber][sym.Value] = Movement{MovementType: 1, NextRow: value}
-
+		for _, moves := range transit[idnumber] {
+			sort.Slice(moves, func(i, j int) bool {
+				if moves[i].MovementType != moves[j].MovementType {
+					return moves[i].MovementType < moves[j].MovementType
 				}
+				return moves[i].NextRow < moves[j].NextRow
+			})
+		}
+	}
 
-			}
+	return &transit, &gototable, nil
+}
 
+// Lists the cells with more than one movement, sorted by state and symbol.
+// The grammar is SLR when there are none.
+func Conflicts(transit TransitionTbl) []Conflict {
+	conflicts := make([]Conflict, 0)
+	for state, row := range transit {
+		for symbol, moves := range row {
+			if len(moves) > 1 {
+				conflicts = append(conflicts, Conflict{State: state, Symbol: symbol, Movements: moves})
+			}
 		}
-
 	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		a, _ := strconv.Atoi(conflicts[i].State)
+		b, _ := strconv.Atoi(conflicts[j].State)
+		if a != b {
+			return a < b
+		}
+		return conflicts[i].Symbol < conflicts[j].Symbol
+	})
+	return conflicts
+}
 
-	return &transit, &gototable, nil
+// Keeps the first movement of each cell. Without conflicts it is the same table.
+func Deterministic(transit TransitionTbl) DeterministicTbl {
+	deterministic := make(DeterministicTbl, len(transit))
+	for state, row := range transit {
+		deterministic[state] = make(DeterministicTblRow, len(row))
+		for symbol, moves := range row {
+			if len(moves) > 0 {
+				deterministic[state][symbol] = moves[0]
+			}
+		}
+	}
+	return deterministic
 }
 
+// Computes the FIRST set of every non terminal. Nullable non terminals, the ones
+// that derive the empty string, have parser.EPSILON on their set.
 func GetFirst(def *parser.ParserDefinition) map[string]parser.SymbolSet {
 
 	firstSet := make(map[string]parser.SymbolSet, len(def.NonTerminals))
@@ -89,16 +118,7 @@ func GetFirst(def *parser.ParserDefinition) map[string]parser.SymbolSet {
 		changed = false
 		for _, prod := range def.Productions {
 			head := prod.Head.Value
-			firstSymbol := prod.Body[0]
-			// For terminal symbols
-			if firstSymbol.Id != parser.NON_TERMINAL_ID {
-				if _, exists := firstSet[head][firstSymbol]; !exists {
-					firstSet[head][firstSymbol] = struct{}{}
-					changed = true
-				}
-			}
-			// FOR NON Terminal symbols.
-			for terminal := range firstSet[firstSymbol.Value] {
+			for terminal := range GetFirstOfSequence(prod.Body, firstSet) {
 				if _, exists := firstSet[head][terminal]; !exists {
 					firstSet[head][terminal] = struct{}{}
 					changed = true
@@ -107,16 +127,32 @@ func GetFirst(def *parser.ParserDefinition) map[string]parser.SymbolSet {
 		}
 	}
 
-	fmt.Println("=== FIRST Sets ===")
-	for nt, set := range firstSet {
-		fmt.Printf("FIRST(%s) = { ", nt)
-		for sym := range set {
-			fmt.Printf("%s ", sym.Value)
+	return firstSet
+}
+
+// Computes the FIRST set of a sequence of symbols, like the body of a production
+// or the part of it after a symbol. It has parser.EPSILON when the whole sequence
+// is nullable.
+func GetFirstOfSequence(symbols []parser.ParserSymbol, firstSet map[string]parser.SymbolSet) parser.SymbolSet {
+	first := make(parser.SymbolSet)
+	for _, symbol := range symbols {
+		// For terminal symbols
+		if symbol.Id != parser.NON_TERMINAL_ID {
+			first[symbol] = struct{}{}
+			return first
+		}
+		// FOR NON Terminal symbols, the next one counts only if this one is nullable.
+		for terminal := range firstSet[symbol.Value] {
+			if terminal != parser.EPSILON {
+				first[terminal] = struct{}{}
+			}
+		}
+		if _, nullable := firstSet[symbol.Value][parser.EPSILON]; !nullable {
+			return first
 		}
-		fmt.Println("}")
 	}
-
-	return firstSet
+	first[parser.EPSILON] = struct{}{}
+	return first
 }
 
 func GetFollow(def *parser.ParserDefinition,
@@ -132,7 +168,6 @@ func GetFollow(def *parser.ParserDefinition,
 		}
 	}
 
-	fmt.Printf("%v\n", def.Productions[0].Head.Value)
 	initialValue := parser.ParserSymbol{Id: 0, Value: "$"}
 
 	// Add initial symbol
@@ -152,25 +187,20 @@ func GetFollow(def *parser.ParserDefinition,
 					continue
 				}
 
-				// If form A -> a B b
-				if i+1 < len(prod.Body) {
-					target := prod.Body[i+1]
-					if target.Id == parser.NON_TERMINAL_ID {
-						// FOR NON Terminal symbols.
-						for terminal := range firstSet[target.Value] {
-							if _, exists := followSet[symbol.Value][terminal]; !exists {
-								followSet[symbol.Value][terminal] = struct{}{}
-								changed = true
-							}
-						}
-					} else {
-						if _, exist := followSet[symbol.Value][target]; !exist {
-							followSet[symbol.Value][target] = struct{}{}
-							changed = true
-						}
+				// If form A -> a B b, FIRST(b) goes to FOLLOW(B)
+				rest := GetFirstOfSequence(prod.Body[i+1:], firstSet)
+				for terminal := range rest {
+					if terminal == parser.EPSILON {
+						continue
+					}
+					if _, exists := followSet[symbol.Value][terminal]; !exists {
+						followSet[symbol.Value][terminal] = struct{}{}
+						changed = true
 					}
-					// If form A -> a B
-				} else {
+				}
+
+				// If form A -> a B, or b is nullable, FOLLOW(A) goes to FOLLOW(B)
+				if _, nullable := rest[parser.EPSILON]; nullable {
 					target := prod.Head
 					for terminal := range followSet[target.Value] {
 						if _, exists := followSet[symbol.Value][terminal]; !exists {
@@ -184,16 +214,21 @@ func GetFollow(def *parser.ParserDefinition,
 		}
 	}
 
-	fmt.Println("=== FOLLOW Sets ===")
-	for nt, set := range followSet {
-		fmt.Printf("FOLLOW(%s) = { ", nt)
+	return followSet
+}
+
+// Prints a FIRST or FOLLOW set for each non terminal
+//
+//	PrintSymbolSets("FIRST", first)
+func PrintSymbolSets(title string, sets map[string]parser.SymbolSet) {
+	fmt.Printf("=== %s Sets ===\n", title)
+	for nt, set := range sets {
+		fmt.Printf("%s(%s) = { ", title, nt)
 		for sym := range set {
 			fmt.Printf("%s ", sym.Value)
 		}
 		fmt.Println("}")
 	}
-
-	return followSet
 }
 
 func CheckNonTerminal(id string, definition parser.ParserDefinition) bool {
@@ -238,22 +273,3 @@ func equalBodies(a, b []parser.ParserSymbol) bool {
 	}
 	return true
 }
-
-func GetOneProductionWithNoTransitions(state *automata.State) *parser.ParserProduction {
-	for _, prod := range state.Productions {
-		hasAnyTransition := false
-
-		for _, sym := range prod.Body {
-			if _, ok := state.Transitions[sym]; ok {
-				hasAnyTransition = true
-				break
-			}
-		}
-
-		if !hasAnyTransition {
-			prodCopy := prod // avoid returning reference to loop variable
-			return &prodCopy
-		}
-	}
-	return nil
-}
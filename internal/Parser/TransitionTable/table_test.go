@@ -2,6 +2,7 @@ package transitiontable
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -15,7 +16,10 @@ import (
 
 func Test_check1(t *testing.T) {
 
-	parserdef, _ := reader.Parse("../../../examples/productions2.y")
+	parserdef, err := reader.Parse("../../../examples/superSimple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
 	first := GetFirst(parserdef)
 	follow := GetFollow(parserdef, first)
 	var automa = automata.NewAutomata(parserdef, false)
@@ -33,8 +37,10 @@ func Test_check1(t *testing.T) {
 
 		for state, transitions := range *TransitionTabl {
 			fmt.Printf("State %s:\n", state)
-			for symbol, action := range transitions {
-				fmt.Printf("  %s => {%d %d}\n", symbol, action.MovementType, action.NextRow)
+			for symbol, actions := range transitions {
+				for _, action := range actions {
+					fmt.Printf("  %s => {%d %d}\n", symbol, action.MovementType, action.NextRow)
+				}
 			}
 		}
 
@@ -42,11 +48,11 @@ func Test_check1(t *testing.T) {
 
 	tokens := []Token{Token{Value: "int", TokenID: 2, Offset: 0}, Token{Value: "+", TokenID: 2, Offset: 0}, Token{Value: "int", TokenID: 2, Offset: 0}}
 
-	ParseInput(*TransitionTabl, *parserdef, *gotable, tokens)
+	ParseInput(Deterministic(*TransitionTabl), *parserdef, *gotable, tokens)
 
 	tokens = []Token{Token{Value: "int", TokenID: 2, Offset: 0}, Token{Value: "int", TokenID: 2, Offset: 0}}
 
-	ParseInput(*TransitionTabl, *parserdef, *gotable, tokens)
+	ParseInput(Deterministic(*TransitionTabl), *parserdef, *gotable, tokens)
 
 }
 
@@ -68,7 +74,7 @@ type Token struct {
 	Offset  int    // No of bytes from the start of the file to the current lexeme
 }
 
-func ParseInput(transit TransitionTbl, parserdef parser.ParserDefinition, gotable GotoTbl, token []Token) *[]Token {
+func ParseInput(transit DeterministicTbl, parserdef parser.ParserDefinition, gotable GotoTbl, token []Token) *[]Token {
 
 	input := ""
 
@@ -207,3 +213,89 @@ func ParseInput(transit TransitionTbl, parserdef parser.ParserDefinition, gotabl
 	return nil
 
 }
+
+// Cells of the SLR tables of superSimple.par, reached by following the symbols
+// from the first state since the numbers of the states may change:
+//
+//	0: E → T + E   1: E → T   2: T → int * T   3: T → int   4: T → ( E )
+//
+// Reductions go on FOLLOW(E) = {$, )} and FOLLOW(T) = {+, $, )}, accept only on
+// "$" after E from the first state.
+func Test_slrCells(t *testing.T) {
+	parserdef, err := reader.Parse("../../../examples/superSimple.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := GetFirst(parserdef)
+	follow := GetFollow(parserdef, first)
+	transit, gotos, err := NewTable(automata.NewAutomata(parserdef, false), first, follow, *parserdef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := Conflicts(*transit); len(conflicts) > 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+	if len(*transit) != 11 {
+		t.Errorf("expected 11 states, got %d", len(*transit))
+	}
+
+	// Follows terminals with their shift and non terminals with their goto
+	walk := func(symbols ...string) string {
+		state := "0"
+		for _, symbol := range symbols {
+			if CheckNonTerminal(symbol, *parserdef) {
+				move, ok := (*gotos)[state][symbol]
+				if !ok {
+					t.Fatalf("no goto from state %s on %s", state, symbol)
+				}
+				state = strconv.Itoa(move.NextRow)
+				continue
+			}
+			moves := (*transit)[state][symbol]
+			if len(moves) != 1 || moves[0].MovementType != SHIFT {
+				t.Fatalf("no shift from state %s on %s", state, symbol)
+			}
+			state = strconv.Itoa(moves[0].NextRow)
+		}
+		return state
+	}
+	cells := func(state string) string {
+		row := make([]string, 0)
+		for symbol, moves := range (*transit)[state] {
+			for _, move := range moves {
+				switch move.MovementType {
+				case SHIFT:
+					row = append(row, symbol+":s")
+				case REDUCE:
+					row = append(row, fmt.Sprintf("%s:r%d", symbol, move.NextRow))
+				case ACCEPT:
+					row = append(row, symbol+":acc")
+				}
+			}
+		}
+		sort.Strings(row)
+		return strings.Join(row, " ")
+	}
+
+	expected := []struct {
+		path  []string
+		cells string
+	}{
+		{[]string{}, "(:s int:s"},
+		{[]string{"E"}, "$:acc"},
+		{[]string{"T"}, "$:r1 ):r1 +:s"},
+		{[]string{"int"}, "$:r3 ):r3 *:s +:r3"},
+		{[]string{"T", "+"}, "(:s int:s"},
+		{[]string{"T", "+", "E"}, "$:r0 ):r0"},
+		{[]string{"int", "*"}, "(:s int:s"},
+		{[]string{"int", "*", "T"}, "$:r2 ):r2 +:r2"},
+		{[]string{"("}, "(:s int:s"},
+		{[]string{"(", "E"}, "):s"},
+		{[]string{"(", "E", ")"}, "$:r4 ):r4 +:r4"},
+	}
+	for _, e := range expected {
+		if got := cells(walk(e.path...)); got != e.cells {
+			t.Errorf("after %v: expected %s, got %s", e.path, e.cells, got)
+		}
+	}
+}
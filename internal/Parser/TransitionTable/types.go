@@ -5,14 +5,23 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 
+	parser "github.com/DanielRasho/Parser/internal/Parser"
 	"github.com/olekukonko/tablewriter"
 )
 
 // Transition Table
 // NOTE: ADD THE $ as a new terminal that works as sentinel
+// A cell holds every movement possible, more than one is a conflict: the SLR
+// parser needs one per cell, the GLR parser follows all of them.
 type TransitionTbl = map[string]TransitionTblRow
-type TransitionTblRow = map[string]Movement
+type TransitionTblRow = map[string][]Movement
+
+// Transition table with one movement per cell, the one a deterministic parser
+// like the SLR one follows.
+type DeterministicTbl = map[string]DeterministicTblRow
+type DeterministicTblRow = map[string]Movement
 
 // Goto table
 type GotoTbl = map[string]GotoTblRow
@@ -33,7 +42,60 @@ const (
 	ACCEPT
 )
 
+// A cell of the transition table with more than one movement.
+type Conflict struct {
+	State     string
+	Symbol    string
+	Movements []Movement
+}
+
+// Describe writes the movements of the conflict, reductions with their
+// production and where it is defined. Ex:
+//
+//	state 5 on + has 2 movements:
+//		s4
+//		r0  ambiguous.par:4:1  1: E → E + E
+func (c Conflict) Describe(definition *parser.ParserDefinition) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("state %s on %s has %d movements:", c.State, c.Symbol, len(c.Movements)))
+	for _, move := range c.Movements {
+		sb.WriteString("\n\t" + movementToString(move))
+		if move.MovementType == REDUCE {
+			production := definition.Productions[move.NextRow]
+			sb.WriteString(fmt.Sprintf("  %s  %s", production.Pos, production.String()))
+		}
+	}
+	return sb.String()
+}
+
+// Prints a transition table, cells with a conflict show every movement split by "/".
+func PrintTransitionTable(title string, tbl TransitionTbl) {
+	cells := make(map[string]map[string]string, len(tbl))
+	for state, row := range tbl {
+		cells[state] = make(map[string]string, len(row))
+		for symbol, moves := range row {
+			texts := make([]string, len(moves))
+			for i, move := range moves {
+				texts[i] = movementToString(move)
+			}
+			cells[state][symbol] = strings.Join(texts, "/")
+		}
+	}
+	printCells(title, cells)
+}
+
 func PrintMovementTable(title string, tbl map[string]map[string]Movement) {
+	cells := make(map[string]map[string]string, len(tbl))
+	for state, row := range tbl {
+		cells[state] = make(map[string]string, len(row))
+		for symbol, move := range row {
+			cells[state][symbol] = movementToString(move)
+		}
+	}
+	printCells(title, cells)
+}
+
+func printCells(title string, tbl map[string]map[string]string) {
 	// Step 1: Collect all unique column names
 	columnSet := make(map[string]struct{})
 	for _, row := range tbl {
@@ -65,11 +127,7 @@ func PrintMovementTable(title string, tbl map[string]map[string]Movement) {
 	for _, rowKey := range rowKeys {
 		row := []string{rowKey}
 		for _, col := range columns {
-			if move, ok := tbl[rowKey][col]; ok {
-				row = append(row, movementToString(move))
-			} else {
-				row = append(row, "")
-			}
+			row = append(row, tbl[rowKey][col])
 		}
 		table.Append(row)
 	}
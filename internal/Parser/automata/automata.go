@@ -73,16 +73,21 @@ func NewAutomata(df *parser.ParserDefinition, showLogs bool) *Automata {
 	for i := range nodes {
 		node := nodes[i]
 		productions := make([]parser.ParserProduction, 0, len(node.metaProds))
+		reductions := make([]parser.ParserProduction, 0)
 		transitions := make(map[parser.ParserSymbol]*State, len(node.transitions))
 
 		for _, p := range node.metaProds {
 			productions = append(productions, *productionsDictionary[p.getDictIndex()])
+			if p.completed && !p.isRoot {
+				reductions = append(reductions, *productionsDictionary[p.getDictIndex()])
+			}
 		}
 
 		newState := State{
 			Id:          node.name,
 			Productions: productions,
 			Transitions: transitions,
+			Reductions:  reductions,
 			IsFinal:     node.isFinal,
 			IsAccepted:  node.completed}
 		states = append(states, &newState)
@@ -299,8 +304,8 @@ func (n *metaNode) evaluate(symbol *parser.ParserSymbol, dictionary []*parser.Pa
 			}
 		}
 
-		// Check if its final
-		if metaProd.id == ROOT_PRODUCTION {
+		// Check if its final, the root production was scanned
+		if metaProd.isRoot && metaProd.completed {
 			isFinal = true
 		}
 
@@ -18,8 +18,13 @@ type State struct {
 	Id          int
 	Productions []parser.ParserProduction      // Sorted by highest too lower priority ( 0 has the hightes priority )
 	Transitions map[parser.ParserSymbol]*State // {"a": STATE1, "b": STATE2, "NUMBER": STATEFINAL}
-	IsFinal     bool
-	IsAccepted  bool
+	// Productions scanned completely on this state, which can be reduced. The
+	// root production is not included, IsFinal is set instead.
+	Reductions []parser.ParserProduction
+	// Set on the state that scanned the whole root production S' → S, the only
+	// one where the table accepts on "$".
+	IsFinal    bool
+	IsAccepted bool
 }
 
 // =========================
@@ -3,16 +3,31 @@ package parser
 import (
 	"fmt"
 	"strings"
+
+	io "github.com/DanielRasho/Parser/internal/IO"
 )
 
 // Its a programatically representation of a yapar file.
 type ParserDefinition struct {
-	NonTerminals  []ParserSymbol
-	Terminals     []ParserSymbol
-	Productions   []ParserProduction
+	NonTerminals []ParserSymbol
+	Terminals    []ParserSymbol
+	Productions  []ParserProduction
+	// Tokens the parser doesn't consume, the ones on any channel but the default one
 	IgnoredSymbol map[int]ParserSymbol
+	// Channel of each token off the default channel, by token Id
+	//
+	//	{3: "hidden", 4: "comments"}
+	Channels map[int]string
+	// Where each token was declared on the yapar file, by token Id
+	TokenPositions map[int]io.Position
 }
 
+// Channels tokens can be routed to. Any other name can be declared with %channel.
+const (
+	DEFAULT_CHANNEL = "default" // Tokens consumed by the parser
+	HIDDEN_CHANNEL  = "hidden"  // Tokens declared with IGNORE, like white spaces
+)
+
 // Represents a single production declaration
 //
 //	{Head : "A", Body: ["A",+"A"]}
@@ -22,12 +37,17 @@ type ParserProduction struct {
 	Head ParserSymbol
 	// List of symbols that comprehend a production
 	Body []ParserSymbol
+	// Where the production was defined on the yapar file
+	Pos io.Position
 }
 
 func (p *ParserProduction) String() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%d: %s → ", p.Id, p.Head.Value))
 
+	if p.IsEmpty() {
+		sb.WriteString(EPSILON.Value)
+	}
 	for i, symbol := range p.Body {
 		if i > 0 {
 			sb.WriteString(" ")
@@ -57,7 +77,16 @@ type ParserSymbol struct {
 	IsTerminal bool
 }
 
+// Checks if the production derives the empty string directly, written as
+// "%empty" on the yapar file.
+func (p *ParserProduction) IsEmpty() bool {
+	return len(p.Body) == 0
+}
+
 const NON_TERMINAL_ID = -1
 
+// Stands for the empty string within FIRST sets, nullable non terminals have it.
+var EPSILON = ParserSymbol{Id: 0, Value: "ε", IsTerminal: true}
+
 // Used for first-follow computations
 type SymbolSet = map[ParserSymbol]struct{}
@@ -0,0 +1,617 @@
+{{ define "GLRParserTemplate" }}
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+
+// =============================
+// 			TYPES
+// =============================
+
+// NOTE: ADD THE $ as a new terminal that works as sentinel
+// A cell holds every movement possible, the parser follows all of them.
+type TransitionTbl = map[string]TransitionTblRow
+type TransitionTblRow = map[string][]Movement
+
+// Goto table
+type GotoTbl = map[string]GotoTblRow
+type GotoTblRow = map[string]Movement
+
+// Movements
+type Movement struct {
+	MovementType int
+	NextRow      int
+}
+
+type MovementType = int
+
+const (
+	SHIFT MovementType = iota
+	REDUCE
+	GOTO
+	ACCEPT
+)
+
+// PARSER DEFINITION
+// Its a programatically representation of a yapar file.
+type ParserDefinition struct {
+	NonTerminals []ParserSymbol
+	Terminals    []ParserSymbol
+	Productions  []ParserProduction
+	IgnoredSymbols map[int]ParserSymbol
+	Channels       map[int]string // Channel of each token off the default channel
+}
+
+// Represents a single production declaration
+//
+//	{Head : "A", Body: ["A",+"A"]}
+type ParserProduction struct {
+	// Given by the order of definition in the yapar file, starting from 1
+	Id   int
+	Head ParserSymbol
+	// List of symbols that comprehend a production
+	Body []ParserSymbol
+}
+
+func (p *ParserProduction) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d: %s → ", p.Id, p.Head.Value))
+
+	for i, symbol := range p.Body {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(symbol.Value)
+	}
+	return sb.String()
+}
+
+// Smallest information unit, the parser can read. Which is basically a symbol
+// which can a terminal or non terminal.
+// Example TERMINAL:
+//
+//	{Id: 2, Value: "NUMBER"}
+//
+// Example TERMINAL:
+//
+//	{Id: -1, Value: "A"}
+type ParserSymbol struct {
+	// If token is terminal, the id comes from the order of declaration in
+	// the Yapar file.
+	// Start from 1
+	Id int
+	// The string value itself of the symbol
+	Value string
+
+	IsTerminal bool
+}
+
+const NON_TERMINAL_ID = -1
+
+// Used for first-follow computations
+type SymbolSet = map[ParserSymbol]struct{}
+
+type Parser struct {
+	parsedefinition *ParserDefinition
+	transitiontable *TransitionTbl // Every movement on each state and terminal
+	gototable       *GotoTbl       // State to go after reducing a non terminal
+	filters         []Filter       // Used by Parse to pick a tree out of the forest
+}
+
+
+func NewParser(filePath string) (*Parser, error) {
+	return &Parser{
+		parsedefinition: newParserdefinition(),
+		transitiontable: newTransitTable(),
+		gototable:       newGoToTable(),
+	}, nil
+}
+
+// AddFilters adds disambiguation filters, Parse applies them in order to every
+// ambiguous node of the forest. Productions are given by their index on the
+// definition (their Id - 1). Ex:
+//
+//	parser.AddFilters(Priority(1, 0), LeftAssociative(0, 1))
+func (p *Parser) AddFilters(filters ...Filter) {
+	p.filters = append(p.filters, filters...)
+}
+
+{{ template "ParserCommon" . }}
+// AmbiguityError is returned by Parse when the filters leave more than one tree.
+type AmbiguityError struct {
+	Node  *ForestNode // The ambiguous node covering the most tokens
+	Token Token       // First token of the node
+}
+
+func (e *AmbiguityError) Error() string {
+	return fmt.Sprintf("error line %d column %d \n\tambiguous input, %s can be read in %d ways",
+		e.Token.Line,
+		e.Token.Column,
+		e.Node.Symbol.Value,
+		len(e.Node.Packed))
+}
+
+// =============================
+// 		PARSE FOREST
+// =============================
+
+// Node of the shared packed parse forest: a symbol deriving the leaves from
+// Start to End (not included). A symbol covering the same leaves has a single
+// node on every parse, holding each way it derives them.
+type ForestNode struct {
+	Symbol ParserSymbol
+	Start  int
+	End    int
+	Packed []*PackedNode // Productions deriving the leaves, empty for leaves
+	Leaf   *ParseNode    // Only set for leaves
+}
+
+// One way of deriving the leaves of a node.
+type PackedNode struct {
+	Production int // Index on ParserDefinition.Productions
+	Children   []*ForestNode
+}
+
+// A Filter gets the alternatives of an ambiguous node and returns the ones to keep.
+type Filter func(node *ForestNode, alternatives []*PackedNode) []*PackedNode
+
+// Parse builds the parse tree of a list of tokens of any channel. Only tokens on
+// the default channel are parsed, the rest are attached to the closest leaf.
+// The filters added pick the tree out of the forest, an *AmbiguityError is
+// returned when there is more than one left.
+func (p *Parser) Parse(tokens []Token) (*ParseNode, error) {
+	root, err := p.ParseForest(tokens)
+	if err != nil {
+		return nil, err
+	}
+	root.Disambiguate(p.filters...)
+	if ambiguous := root.Ambiguities(); len(ambiguous) > 0 {
+		return nil, &AmbiguityError{Node: ambiguous[0], Token: *ambiguous[0].firstLeaf().Token}
+	}
+	return root.tree(map[*ForestNode]bool{})
+}
+
+// Node of the graph structured stack: a state reached after reading the leaves
+// before level. Edges go back to nodes of earlier levels, labeled with the
+// forest node of the symbol read in between.
+type stackNode struct {
+	state string
+	level int
+	edges []*stackEdge
+}
+
+type stackEdge struct {
+	to    *stackNode
+	label *ForestNode
+}
+
+type forestKey struct {
+	symbol string
+	start  int
+	end    int
+}
+
+// ParseForest returns the root of the forest with every parse of the tokens.
+//
+// Tokens are read one at a time by every stack at once, stacks are merged into
+// a graph so the parses share what they have in common. At each level every
+// reduction is done first, then the stacks able to shift the token move to the
+// next level and the rest die.
+func (p *Parser) ParseForest(tokens []Token) (*ForestNode, error) {
+	leaves := p.attachHidden(tokens)
+
+	terminals := make(map[int]ParserSymbol)
+	for _, terminal := range p.parsedefinition.Terminals {
+		terminals[terminal.Id] = terminal
+	}
+
+	start := &stackNode{state: "0", level: 0}
+	level := []*stackNode{start}
+	nodes := make(map[forestKey]*ForestNode)
+
+	for next := 0; ; next++ {
+		lookahead := "$"
+		if next < len(leaves) {
+			terminal, ok := terminals[leaves[next].Token.TokenID]
+			if !ok {
+				return nil, p.syntaxError(leaves, next, p.expected(level))
+			}
+			leaves[next].Symbol = terminal
+			lookahead = terminal.Value
+		}
+		level = p.reduce(level, lookahead, next, nodes)
+
+		if next == len(leaves) {
+			for _, top := range level {
+				for _, move := range (*p.transitiontable)[top.state]["$"] {
+					if move.MovementType != ACCEPT {
+						continue
+					}
+					for _, edge := range top.edges {
+						if edge.to == start {
+							return edge.label, nil
+						}
+					}
+				}
+			}
+			return nil, p.syntaxError(leaves, next, p.expected(level))
+		}
+
+		leaf := &ForestNode{Symbol: leaves[next].Symbol, Start: next, End: next + 1, Leaf: leaves[next]}
+		shifted := make([]*stackNode, 0)
+		for _, top := range level {
+			for _, move := range (*p.transitiontable)[top.state][lookahead] {
+				if move.MovementType != SHIFT {
+					continue
+				}
+				state := strconv.Itoa(move.NextRow)
+				node := findStackNode(shifted, state)
+				if node == nil {
+					node = &stackNode{state: state, level: next + 1}
+					shifted = append(shifted, node)
+				}
+				node.edges = append(node.edges, &stackEdge{to: top, label: leaf})
+			}
+		}
+		if len(shifted) == 0 {
+			return nil, p.syntaxError(leaves, next, p.expected(level))
+		}
+		level = shifted
+	}
+}
+
+// Does every reduction possible on a level, returning it with the nodes they
+// add. When an edge is added to a node only the paths through it are reduced again.
+func (p *Parser) reduce(level []*stackNode, lookahead string, position int, nodes map[forestKey]*ForestNode) []*stackNode {
+	type reduction struct {
+		edge       *stackEdge
+		production int
+	}
+	pending := make([]reduction, 0)
+	enqueue := func(node *stackNode, edge *stackEdge) {
+		for _, move := range (*p.transitiontable)[node.state][lookahead] {
+			if move.MovementType == REDUCE {
+				pending = append(pending, reduction{edge: edge, production: move.NextRow})
+			}
+		}
+	}
+	for _, node := range level {
+		for _, edge := range node.edges {
+			enqueue(node, edge)
+		}
+	}
+
+	for len(pending) > 0 {
+		r := pending[0]
+		pending = pending[1:]
+		production := p.parsedefinition.Productions[r.production]
+
+		for _, path := range stackPaths(r.edge, len(production.Body)) {
+			key := forestKey{symbol: production.Head.Value, start: path.to.level, end: position}
+			symbol, ok := nodes[key]
+			if !ok {
+				symbol = &ForestNode{Symbol: production.Head, Start: path.to.level, End: position}
+				nodes[key] = symbol
+			}
+			symbol.addPacked(r.production, path.labels)
+
+			move, ok := (*p.gototable)[path.to.state][production.Head.Value]
+			if !ok {
+				continue
+			}
+			state := strconv.Itoa(move.NextRow)
+			target := findStackNode(level, state)
+			if target == nil {
+				target = &stackNode{state: state, level: position}
+				level = append(level, target)
+			}
+			// The edge was there, the new alternative is already on its label
+			exists := false
+			for _, edge := range target.edges {
+				exists = exists || edge.to == path.to
+			}
+			if exists {
+				continue
+			}
+			edge := &stackEdge{to: path.to, label: symbol}
+			target.edges = append(target.edges, edge)
+			enqueue(target, edge)
+		}
+	}
+	return level
+}
+
+// The symbols popped by a reduction and the node it uncovers.
+type stackPath struct {
+	to     *stackNode
+	labels []*ForestNode
+}
+
+// Paths of a number of edges starting with an edge.
+func stackPaths(edge *stackEdge, length int) []stackPath {
+	if length == 1 {
+		path := stackPath{to: edge.to, labels: []*ForestNode{edge.label}}
+		return []stackPath{path}
+	}
+	paths := make([]stackPath, 0)
+	for _, next := range edge.to.edges {
+		for _, rest := range stackPaths(next, length-1) {
+			labels := append(append([]*ForestNode{}, rest.labels...), edge.label)
+			paths = append(paths, stackPath{to: rest.to, labels: labels})
+		}
+	}
+	return paths
+}
+
+func findStackNode(level []*stackNode, state string) *stackNode {
+	for _, node := range level {
+		if node.state == state {
+			return node
+		}
+	}
+	return nil
+}
+
+// Adds a way of deriving the node unless it has it already.
+func (n *ForestNode) addPacked(production int, children []*ForestNode) {
+	for _, packed := range n.Packed {
+		if packed.Production != production || len(packed.Children) != len(children) {
+			continue
+		}
+		same := true
+		for i := range children {
+			same = same && packed.Children[i] == children[i]
+		}
+		if same {
+			return
+		}
+	}
+	n.Packed = append(n.Packed, &PackedNode{Production: production, Children: children})
+}
+
+// Ambiguities lists the nodes with more than one alternative, the ones covering
+// more leaves first.
+func (n *ForestNode) Ambiguities() []*ForestNode {
+	ambiguous := make([]*ForestNode, 0)
+	visited := make(map[*ForestNode]bool)
+	pending := []*ForestNode{n}
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+		if len(node.Packed) > 1 {
+			ambiguous = append(ambiguous, node)
+		}
+		for _, packed := range node.Packed {
+			pending = append(pending, packed.Children...)
+		}
+	}
+	sort.SliceStable(ambiguous, func(i, j int) bool {
+		a, b := ambiguous[i], ambiguous[j]
+		if a.End-a.Start != b.End-b.Start {
+			return a.End-a.Start > b.End-b.Start
+		}
+		return a.Start < b.Start
+	})
+	return ambiguous
+}
+
+// Disambiguate applies the filters to every ambiguous node, children before
+// their parents, dropping the alternatives they don't keep. A filter that would
+// drop every alternative of a node is ignored there.
+func (n *ForestNode) Disambiguate(filters ...Filter) {
+	visited := make(map[*ForestNode]bool)
+	var visit func(node *ForestNode)
+	visit = func(node *ForestNode) {
+		if visited[node] {
+			return
+		}
+		visited[node] = true
+		for _, packed := range node.Packed {
+			for _, child := range packed.Children {
+				visit(child)
+			}
+		}
+		for _, filter := range filters {
+			if len(node.Packed) < 2 {
+				break
+			}
+			if kept := filter(node, node.Packed); len(kept) > 0 {
+				node.Packed = kept
+			}
+		}
+	}
+	visit(n)
+}
+
+// Builds the parse tree of a node without ambiguities.
+func (n *ForestNode) tree(visiting map[*ForestNode]bool) (*ParseNode, error) {
+	if n.Leaf != nil {
+		return n.Leaf, nil
+	}
+	// Only left when a filter drops the alternatives leaving the cycle
+	if visiting[n] {
+		return nil, fmt.Errorf("error line %d column %d \n\t%s derives itself, it has no finite tree", n.firstLeaf().Token.Line, n.firstLeaf().Token.Column, n.Symbol.Value)
+	}
+	visiting[n] = true
+	defer delete(visiting, n)
+
+	node := &ParseNode{Symbol: n.Symbol}
+	for _, child := range n.Packed[0].Children {
+		subtree, err := child.tree(visiting)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, subtree)
+	}
+	return node, nil
+}
+
+func (n *ForestNode) firstLeaf() *ParseNode {
+	for n.Leaf == nil {
+		n = n.Packed[0].Children[0]
+	}
+	return n.Leaf
+}
+
+// Prefer keeps the alternatives built with one of the productions, when there is any.
+func Prefer(productions ...int) Filter {
+	return func(node *ForestNode, alternatives []*PackedNode) []*PackedNode {
+		kept := make([]*PackedNode, 0)
+		for _, packed := range alternatives {
+			if containsProduction(productions, packed.Production) {
+				kept = append(kept, packed)
+			}
+		}
+		return kept
+	}
+}
+
+// Priority drops the alternatives built with the higher production that have a
+// child built with the lower one. Ex: with E → E * E over E → E + E, 1 + 2 * 3
+// is not read as (1 + 2) * 3.
+func Priority(higher, lower int) Filter {
+	return func(node *ForestNode, alternatives []*PackedNode) []*PackedNode {
+		kept := make([]*PackedNode, 0)
+		for _, packed := range alternatives {
+			dropped := false
+			if packed.Production == higher {
+				for _, child := range packed.Children {
+					dropped = dropped || builtWith(child, []int{lower})
+				}
+			}
+			if !dropped {
+				kept = append(kept, packed)
+			}
+		}
+		return kept
+	}
+}
+
+// LeftAssociative drops the alternatives built with one of the productions
+// whose last child is built with one of them too. Ex: 1 - 2 + 3 is read as (1 - 2) + 3
+func LeftAssociative(productions ...int) Filter {
+	return associative(productions, func(packed *PackedNode) *ForestNode { return packed.Children[len(packed.Children)-1] })
+}
+
+// RightAssociative drops the alternatives built with one of the productions
+// whose first child is built with one of them too. Ex: 2 ^ 3 ^ 2 is read as 2 ^ (3 ^ 2)
+func RightAssociative(productions ...int) Filter {
+	return associative(productions, func(packed *PackedNode) *ForestNode { return packed.Children[0] })
+}
+
+func associative(productions []int, operand func(packed *PackedNode) *ForestNode) Filter {
+	return func(node *ForestNode, alternatives []*PackedNode) []*PackedNode {
+		kept := make([]*PackedNode, 0)
+		for _, packed := range alternatives {
+			if !containsProduction(productions, packed.Production) || !builtWith(operand(packed), productions) {
+				kept = append(kept, packed)
+			}
+		}
+		return kept
+	}
+}
+
+// Whether every alternative of a non terminal node uses one of the productions.
+func builtWith(node *ForestNode, productions []int) bool {
+	if len(node.Packed) == 0 {
+		return false
+	}
+	for _, packed := range node.Packed {
+		if !containsProduction(productions, packed.Production) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsProduction(productions []int, production int) bool {
+	for _, p := range productions {
+		if p == production {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminals some node of the level has a movement for, sorted.
+func (p *Parser) expected(level []*stackNode) []string {
+	set := make(map[string]struct{})
+	for _, node := range level {
+		for symbol := range (*p.transitiontable)[node.state] {
+			set[symbol] = struct{}{}
+		}
+	}
+	expected := make([]string, 0, len(set))
+	for symbol := range set {
+		expected = append(expected, symbol)
+	}
+	sort.Strings(expected)
+	return expected
+}
+
+func newTransitTable() *TransitionTbl {
+	return &TransitionTbl{
+		{{- range $state, $row := .TransitTable }}
+		"{{ $state }}": TransitionTblRow{
+			{{- range $symbol, $moves := $row }}
+			"{{ $symbol }}": []Movement{
+				{{- range $moves }}
+				{MovementType: {{ .MovementType }}, NextRow: {{ .NextRow }}},
+				{{- end }}
+			},
+			{{- end }}
+		},
+		{{- end }}
+	}
+}
+
+
+func newGoToTable() *GotoTbl {
+	return &GotoTbl{
+		{{- range $state, $row := .Gotable }}
+		"{{ $state }}": GotoTblRow{
+			{{- range $symbol, $move := $row }}
+			"{{ $symbol }}": Movement{MovementType: {{ $move.MovementType }}, NextRow: {{ $move.NextRow }}},
+			{{- end }}
+		},
+		{{- end }}
+	}
+}
+
+
+func newParserdefinition() *ParserDefinition {
+	return &ParserDefinition{
+		NonTerminals: []ParserSymbol{
+			{{- range .ParserDefinition.NonTerminals }}
+			{Id: {{ .Id }}, Value: "{{ .Value }}", IsTerminal: {{ .IsTerminal }}},
+			{{- end }}
+		},
+		Terminals: []ParserSymbol{
+			{{- range .ParserDefinition.Terminals }}
+			{Id: {{ .Id }}, Value: "{{ .Value }}", IsTerminal: {{ .IsTerminal }}},
+			{{- end }}
+		},
+		Productions: []ParserProduction{
+			{{- range .ParserDefinition.Productions }}
+			{Id: {{ .Id }}, Head: ParserSymbol{Id: {{ .Head.Id }}, Value: "{{ .Head.Value }}", IsTerminal: {{ .Head.IsTerminal }}},
+			 Body: []ParserSymbol{
+				{{- range .Body }}
+				{Id: {{ .Id }}, Value: "{{ .Value }}", IsTerminal: {{ .IsTerminal }}},
+				{{- end }}
+			 }},
+			{{- end }}
+		},
+		IgnoredSymbols: {{ goLiteral .ParserDefinition.IgnoredSymbol }},
+		Channels:       {{ goLiteral .ParserDefinition.Channels }},
+	}
+}
+{{ end }}
@@ -0,0 +1,206 @@
+{{ define "LLParserTemplate" }}
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+
+// =============================
+// 			TYPES
+// =============================
+
+// LL(1) table: for each non terminal and the next terminal, the index of the
+// production to expand. "$" stands for the end of the input.
+type PredictiveTbl = map[string]PredictiveTblRow
+type PredictiveTblRow = map[string]int
+
+// PARSER DEFINITION
+// Its a programatically representation of a yapar file.
+type ParserDefinition struct {
+	NonTerminals []ParserSymbol
+	Terminals    []ParserSymbol
+	Productions  []ParserProduction
+	IgnoredSymbols map[int]ParserSymbol
+	Channels       map[int]string // Channel of each token off the default channel
+}
+
+// Represents a single production declaration
+//
+//	{Head : "A", Body: ["A",+"A"]}
+type ParserProduction struct {
+	// Given by the order of definition in the yapar file, starting from 1
+	Id   int
+	Head ParserSymbol
+	// List of symbols that comprehend a production
+	Body []ParserSymbol
+}
+
+func (p *ParserProduction) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d: %s → ", p.Id, p.Head.Value))
+
+	for i, symbol := range p.Body {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(symbol.Value)
+	}
+	return sb.String()
+}
+
+// Smallest information unit, the parser can read. Which is basically a symbol
+// which can a terminal or non terminal.
+// Example TERMINAL:
+//
+//	{Id: 2, Value: "NUMBER"}
+//
+// Example TERMINAL:
+//
+//	{Id: -1, Value: "A"}
+type ParserSymbol struct {
+	// If token is terminal, the id comes from the order of declaration in
+	// the Yapar file.
+	// Start from 1
+	Id int
+	// The string value itself of the symbol
+	Value string
+
+	IsTerminal bool
+}
+
+const NON_TERMINAL_ID = -1
+
+// Used for first-follow computations
+type SymbolSet = map[ParserSymbol]struct{}
+
+type Parser struct {
+	parsedefinition *ParserDefinition
+	predictivetable *PredictiveTbl // Production to expand for each non terminal and lookahead
+}
+
+
+func NewParser(filePath string) (*Parser, error) {
+	return &Parser{
+		parsedefinition: newParserdefinition(),
+		predictivetable: newPredictiveTable(),
+	}, nil
+}
+
+{{ template "ParserCommon" . }}
+// Parse builds the parse tree of a list of tokens of any channel. Only tokens on
+// the default channel are parsed, the rest are attached to the closest leaf.
+//
+// The tree is built top-down: the stack holds the nodes still to be read, a
+// non terminal on top is expanded with the production the table predicts for
+// the next token and a terminal must be that token.
+func (p *Parser) Parse(tokens []Token) (*ParseNode, error) {
+	leaves := p.attachHidden(tokens)
+
+	terminals := make(map[int]ParserSymbol)
+	for _, terminal := range p.parsedefinition.Terminals {
+		terminals[terminal.Id] = terminal
+	}
+
+	root := &ParseNode{Symbol: p.parsedefinition.Productions[0].Head}
+	stack := []*ParseNode{root}
+	next := 0
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		lookahead := "$"
+		if next < len(leaves) {
+			terminal, ok := terminals[leaves[next].Token.TokenID]
+			if !ok {
+				return nil, p.syntaxError(leaves, next, p.expected(top))
+			}
+			leaves[next].Symbol = terminal
+			lookahead = terminal.Value
+		}
+
+		if top.Symbol.IsTerminal {
+			if top.Symbol.Value != lookahead {
+				return nil, p.syntaxError(leaves, next, p.expected(top))
+			}
+			top.Token, top.Before, top.After = leaves[next].Token, leaves[next].Before, leaves[next].After
+			stack = stack[:len(stack)-1]
+			next++
+			continue
+		}
+
+		index, ok := (*p.predictivetable)[top.Symbol.Value][lookahead]
+		if !ok {
+			return nil, p.syntaxError(leaves, next, p.expected(top))
+		}
+		production := p.parsedefinition.Productions[index]
+		stack = stack[:len(stack)-1]
+		top.Children = make([]*ParseNode, len(production.Body))
+		for i, symbol := range production.Body {
+			top.Children[i] = &ParseNode{Symbol: symbol}
+		}
+		for i := len(top.Children) - 1; i >= 0; i-- {
+			stack = append(stack, top.Children[i])
+		}
+	}
+
+	if next < len(leaves) {
+		return nil, p.syntaxError(leaves, next, []string{"$"})
+	}
+	return root, nil
+}
+
+// Terminals that can come next when a node is on top of the stack, sorted.
+func (p *Parser) expected(top *ParseNode) []string {
+	if top.Symbol.IsTerminal {
+		return []string{top.Symbol.Value}
+	}
+	expected := make([]string, 0)
+	for symbol := range (*p.predictivetable)[top.Symbol.Value] {
+		expected = append(expected, symbol)
+	}
+	sort.Strings(expected)
+	return expected
+}
+
+func newPredictiveTable() *PredictiveTbl {
+	return &PredictiveTbl{
+		{{- range $head, $row := .PredictiveTable }}
+		"{{ $head }}": PredictiveTblRow{
+			{{- range $symbol, $production := $row }}
+			"{{ $symbol }}": {{ $production }},
+			{{- end }}
+		},
+		{{- end }}
+	}
+}
+
+
+func newParserdefinition() *ParserDefinition {
+	return &ParserDefinition{
+		NonTerminals: []ParserSymbol{
+			{{- range .ParserDefinition.NonTerminals }}
+			{Id: {{ .Id }}, Value: "{{ .Value }}", IsTerminal: {{ .IsTerminal }}},
+			{{- end }}
+		},
+		Terminals: []ParserSymbol{
+			{{- range .ParserDefinition.Terminals }}
+			{Id: {{ .Id }}, Value: "{{ .Value }}", IsTerminal: {{ .IsTerminal }}},
+			{{- end }}
+		},
+		Productions: []ParserProduction{
+			{{- range .ParserDefinition.Productions }}
+			{Id: {{ .Id }}, Head: ParserSymbol{Id: {{ .Head.Id }}, Value: "{{ .Head.Value }}", IsTerminal: {{ .Head.IsTerminal }}},
+			 Body: []ParserSymbol{
+				{{- range .Body }}
+				{Id: {{ .Id }}, Value: "{{ .Value }}", IsTerminal: {{ .IsTerminal }}},
+				{{- end }}
+			 }},
+			{{- end }}
+		},
+		IgnoredSymbols: {{ goLiteral .ParserDefinition.IgnoredSymbol }},
+		Channels:       {{ goLiteral .ParserDefinition.Channels }},
+	}
+}
+{{ end }}
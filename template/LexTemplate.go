@@ -2,11 +2,11 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
 // =====================
@@ -23,45 +23,78 @@ import (
 
 const NO_LEXEME = -1 // Flag constant that is used when no lexeme is recognized nor 
 const SKIP_LEXEME = -2 // Flag when an action require the lexer to IGNORE the current lexeme
+const ERROR_LEXEME = -3 // Token ID of the characters that no pattern recognizes, only used on recovery mode
 
 // PatternNotFound represents an error when a pattern is not found in a file
 type PatternNotFound struct {
+	File    string // Name of the input where the error was found
 	Line    int
 	Column  int
 	Pattern string
 }
 
 func (e *PatternNotFound) Error() string {
-	return fmt.Sprintf("error line %d column %d \n\tpattern not found. current pattern not recognized by the language: %s",
+	return fmt.Sprintf("%s: error line %d column %d \n\tpattern not found. current pattern not recognized by the language: %s",
+		e.File,
 		e.Line,
 		e.Column,
 		e.Pattern)
 }
 
-// Error when the lexer stop cou
+// Error when the file ends in the middle of a lexeme
 type FileUnfinishedSuddenly struct {
+	File    string // Name of the input where the error was found
+	Line    int
+	Column  int
+	Pattern string
 }
 
 func (e *FileUnfinishedSuddenly) Error() string {
-	return fmt.Sprintf("file ended before full lexeme could be recognized")
+	return fmt.Sprintf("%s: error line %d column %d \n\tfile ended before full lexeme could be recognized: %s",
+		e.File,
+		e.Line,
+		e.Column,
+		e.Pattern)
 }
 
 type Symbol = string
 
 // Definition of a Lexer
 type Lexer struct {
-	file         *os.File        // File to read from
-	reader       *bufio.Reader   // Reader to get the symbols from file
-	automata     dfa             // Automata for lexeme recognition
-	symbolBuffer strings.Builder // Buffer to store the symbols of the current lexeme
-	bytesRead    int             // Number of bytes the lexer has read
+	inputs   []*input // Stack of inputs, the last one is the one being read
+	onEOF    EOFHook  // Called when an input ends
+	lexeme   [3]int   // Input, start and end of the lexeme whose action is being executed
+	recover  bool     // If set, unrecognized characters become ERROR_LEXEME tokens
+	errors   []error  // Errors found while on recovery mode
 }
 
+// A source the lexer reads from, like the main file or a file included by it.
+// Each input keeps its own position, so the parent resumes where it was left.
+//
+// The whole content is loaded at once, lexemes are slices of it so no copies
+// are made while scanning.
+type input struct {
+	name      string    // Name used on positions, usually the file path
+	source    io.Reader // Closed when the input is popped, if it is an io.Closer
+	content   string    // Everything the source had
+	bytesRead int       // Number of bytes the lexer has read, where the next lexeme starts
+	line      int       // Line of the next rune to be recognized, starting at 1
+	column    int       // Column of the next rune to be recognized, starting at 1
+}
+
+// Called when an input has no more runes, with the lexer and the name of the input.
+// Returning true resumes the parent input, returning false stops the lexer. The hook
+// may also push a new input, which is read next regardless of what it returns.
+type EOFHook func(l *Lexer, name string) bool
+
 // Represents a piece of information withing the file
 type Token struct {
 	Value   Symbol // Actual string read by the lexer
 	TokenID int    // Token Id (defined by the user above)
+	File    string // Name of the input the lexeme was read from
 	Offset  int    // No of bytes from the start of the file to the current lexeme
+	Line    int    // Line where the lexeme starts
+	Column  int    // Column (in runes) where the lexeme starts
 }
 
 // Converts the string to a human readable version
@@ -75,190 +108,337 @@ func NewLexer(filePath string) (*Lexer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Lexer{
-		file:         file,
-		reader:       bufio.NewReader(file),
-		automata:     *createDFA(),
-		symbolBuffer: strings.Builder{}}, nil
+	lexer, err := NewLexerFromReader(filePath, file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return lexer, nil
+}
+
+// Creates a new Lexer that reads from any reader, name is used on the positions
+// of tokens and errors. The reader is closed by Close if it is an io.Closer.
+func NewLexerFromReader(name string, reader io.Reader) (*Lexer, error) {
+	lexer := &Lexer{
+		inputs: make([]*input, 0),
+		errors: make([]error, 0)}
+	if err := lexer.PushInput(name, reader); err != nil {
+		return nil, err
+	}
+	return lexer, nil
+}
+
+// PushInput makes the lexer read from reader until it ends, then it continues
+// with the input it was reading before. Used to include files from an action:
+//
+//	file, err := os.Open(path)
+//	if err == nil {
+//		l.PushInput(path, file)
+//	}
+//	return SKIP_LEXEME
+//
+// The reader is read completely right away, and closed once popped if it
+// implements io.Closer.
+func (l *Lexer) PushInput(name string, reader io.Reader) error {
+	// Files are read into a buffer of their size, the builder keeps it as a
+	// string without copying it again
+	var content strings.Builder
+	if file, ok := reader.(*os.File); ok {
+		if info, err := file.Stat(); err == nil {
+			content.Grow(int(info.Size()) + 1)
+		}
+	}
+	if _, err := io.Copy(&content, reader); err != nil {
+		return err
+	}
+	l.inputs = append(l.inputs, &input{
+		name:    name,
+		source:  reader,
+		content: content.String(),
+		line:    1,
+		column:  1,
+	})
+	return nil
 }
 
-// Close, closes the file that was being read by the Lexer.
+// PopInput stops reading the current input and resumes the previous one. The
+// first input can't be popped.
+func (l *Lexer) PopInput() error {
+	if len(l.inputs) <= 1 {
+		return fmt.Errorf("there is no input to resume after %s", l.InputName())
+	}
+	top := l.inputs[len(l.inputs)-1]
+	l.inputs = l.inputs[:len(l.inputs)-1]
+	if closer, ok := top.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// InputName returns the name of the input being read.
+func (l *Lexer) InputName() string {
+	return l.input().name
+}
+
+// SetEOFHook sets the function that decides what to do when an input ends. By
+// default the lexer resumes the parent input, and stops after the first one.
+func (l *Lexer) SetEOFHook(hook EOFHook) {
+	l.onEOF = hook
+}
+
+// Text returns the lexeme recognized, meant to be used within actions.
+func (l *Lexer) Text() string {
+	in, start, end := l.lexeme[0], l.lexeme[1], l.lexeme[2]
+	if in >= len(l.inputs) {
+		return "" // The input was popped by the action
+	}
+	return l.inputs[in].content[start:end]
+}
+
+// EnableRecovery makes the lexer keep scanning after an error. Every character
+// that no pattern recognizes is returned as an ERROR_LEXEME token and its error
+// is stored, so all of them can be reported at once through Errors.
+func (l *Lexer) EnableRecovery() {
+	l.recover = true
+}
+
+// Errors returns the errors found until now while on recovery mode, in the
+// order they were found.
+func (l *Lexer) Errors() []error {
+	return l.errors
+}
+
+// Close, closes every input that was being read by the Lexer.
 func (l *Lexer) Close() {
-	l.file.Close()
+	for len(l.inputs) > 1 {
+		l.PopInput()
+	}
+	if closer, ok := l.input().source.(io.Closer); ok {
+		closer.Close()
+	}
 }
 
 // GetNextToken return the next larger token that can find within the file
 // starting from the last position it was left.
+//
+// The automata keeps reading while it has transitions, remembering the last
+// point where a lexeme was accepted. Once it gets stuck it goes back to that
+// point, so runes read after it are read again by the next call. Ex: with the
+// rules [0-9]+ and [0-9]+"."[0-9]+ the input "12.x" returns "12" and then
+// starts again from ".".
 func (l *Lexer) GetNextToken() (Token, error) {
-
-	// For every new lexeme we start an initial configurations
-	lastTokenID := NO_LEXEME
-	currentState := l.automata.startState
-	lexemeBytesSize := 0 // Lenght of current lexeme in bytes.
-	runesFromLastLexeme := 0
-
 	for {
-		// fmt.Println(currentState.id)
-		// 1. First check if in the current state there are any possible actions
-		if actions := currentState.actions; len(currentState.actions) > 0 {
-			newTokenID := actions[0]() // Get action with higher priority
-			if newTokenID == SKIP_LEXEME {
-				// if action return SKIP_LEXEME ignore everything recognized
-				// until now and restart
-				currentState = l.automata.startState
-				l.bytesRead += lexemeBytesSize
-				lexemeBytesSize = 0
-				l.symbolBuffer.Reset()
-				continue
-			} else {
-				// If TokenID returned, update lastToken read.
-				lastTokenID = newTokenID
-				runesFromLastLexeme = 0
-			}
-		} 
-		
-		// 2. Read the next rune 
-		r, size, err := l.reader.ReadRune()
-		if err != nil {
-			
-			// If a lexeme has just recognized just before EOF return it
-			// Ex :  token EOF
-			//			 ^
-			if lastTokenID != NO_LEXEME && runesFromLastLexeme == 0{
-				break
-			}
-
-			// If nothing has been scanned before EOF return io.EOF error
-			// Ex :  token EOF
-			//			  ^
-			if lastTokenID == NO_LEXEME && runesFromLastLexeme == 0 {
+		in := l.input()
+		content := in.content
+		start := in.bytesRead
+		acceptEnd, acceptRule, pos := scan(content, start)
+
+		if acceptEnd == -1 {
+			// Nothing left, the input is over
+			if start == len(content) {
+				if l.endInput() {
+					continue
+				}
 				return Token{}, io.EOF
 			}
 
-			// If EOF was found before concluding to read a complete token
-			// Ex :  token noEOF
-			//			    ^
-			if lastTokenID == NO_LEXEME && runesFromLastLexeme > 0 {
-				return Token{}, &FileUnfinishedSuddenly{}
+			// No lexeme starts here, report the runes read and the one that
+			// stopped the automata, then continue after the first of them.
+			reachedEOF := pos == len(content)
+			if !reachedEOF {
+				_, size := utf8.DecodeRuneInString(content[pos:])
+				pos += size
+			}
+			pattern := content[start:pos]
+
+			var lexError error = &PatternNotFound{File: in.name, Line: in.line, Column: in.column, Pattern: pattern}
+			if reachedEOF {
+				// EOF was found before concluding to read a complete token
+				// Ex :  token noEOF
+				//			    ^
+				lexError = &FileUnfinishedSuddenly{File: in.name, Line: in.line, Column: in.column, Pattern: pattern}
 			}
 
-			// If no lexeme hast been recognized after endint the file, the file has invalid lexemes.
-			return Token{}, err
+			_, size := utf8.DecodeRuneInString(content[start:])
+			token := Token{Value: content[start : start+size], TokenID: ERROR_LEXEME, File: in.name, Offset: start, Line: in.line, Column: in.column}
+			in.advance(start + size)
+			if !l.recover {
+				return Token{}, lexError
+			}
+			l.errors = append(l.errors, lexError)
+			return token, nil
 		}
 
-		nextState, ok := currentState.transitions[string(r)]
-
-		// 3. Check if exist another state to jump to
-		if !ok && lastTokenID == NO_LEXEME {
-			l.symbolBuffer.WriteRune(r)
-			line, columns, _ := l.getLineAndColumn(l.bytesRead)
-			return Token{}, &PatternNotFound{Line: line, Column: columns, Pattern: l.symbolBuffer.String()}
-		} else if !ok {
-			l.reader.UnreadRune()
-			break
+		// The lexeme is consumed before running the action, so inputs pushed
+		// by it start clean
+		token := Token{Value: content[start:acceptEnd], File: in.name, Offset: start, Line: in.line, Column: in.column}
+		in.advance(acceptEnd)
+		l.lexeme = [3]int{len(l.inputs) - 1, start, acceptEnd}
+		token.TokenID = l.runAction(int(keywordRule(acceptRule, token.Value)))
+		if token.TokenID == SKIP_LEXEME {
+			// Ignore everything recognized until now and restart
+			continue
 		}
 
-		// 4. update state
-		l.symbolBuffer.WriteRune(r)
-		lexemeBytesSize += size
-		currentState = nextState
-		runesFromLastLexeme++
+		return token, nil
 	}
+}
 
-	// 5. Build recognized token
-	offset := l.bytesRead
-	token := Token{
-		TokenID: lastTokenID,
-		Value:   l.symbolBuffer.String(),
-		Offset:  offset,
+// Moves the position of the input to the byte end, updating its line and column.
+func (in *input) advance(end int) {
+	line, column := in.line, in.column
+	for i := in.bytesRead; i < end; i++ {
+		c := in.content[i]
+		if c == '\n' {
+			line++
+			column = 1
+		} else if c < utf8.RuneSelf || c >= 0xC0 {
+			column++ // Only the first byte of a rune is counted
+		}
 	}
-	l.symbolBuffer.Reset()
-	l.bytesRead += lexemeBytesSize
-
-	return token, nil
+	in.line, in.column = line, column
+	in.bytesRead = end
 }
 
-// getLineAndColumn takes an open file and an offset (in bytes),
-// and returns the line and column where that byte is located.
-func (l *Lexer) getLineAndColumn(offset int) (line, column int, err error) {
+// Input being read
+func (l *Lexer) input() *input {
+	return l.inputs[len(l.inputs)-1]
+}
 
-	// Reset file position to the beginning (because the lexer reader moved the file cursor previously)
-	_, err = l.file.Seek(0, io.SeekStart)
-	if err != nil {
-		return 0, 0, err
+// Decides what to do after the current input ends. Returns true if there is
+// another input to keep reading from.
+func (l *Lexer) endInput() bool {
+	ended := l.input()
+	resume := true
+	if l.onEOF != nil {
+		resume = l.onEOF(l, ended.name)
 	}
+	if l.input() != ended {
+		return true // The hook pushed a new input
+	}
+	if !resume || len(l.inputs) == 1 {
+		return false
+	}
+	l.PopInput()
+	return true
+}
 
-	// Create a buffered reader from the open file
-	reader := bufio.NewReader(l.file)
-
-	var currentByte int = 0
-	line = 1
-	column = 1
+// =====================
+//	  DFA
+// =====================
 
-	// Read byte-by-byte
-	for {
-		// Read one byte at a time
-		byteRead, err := reader.ReadByte()
-		if err != nil && err.Error() != "EOF" {
-			return 0, 0, err
-		}
+// Both kinds of generated lexers provide a scan function:
+//
+//	func scan(content string, start int) (acceptEnd int, acceptRule int32, stop int)
+//
+// It runs the automata over content from the byte start, returning where the
+// longest lexeme accepted ends and its rule (-1 both if there is none), and the
+// byte where the automata stopped.
+{{ if .DirectCoded }}
+// Each state of the automata is a labeled block of code, transitions are gotos.
+// Runes above ASCII are looked up on the ranges of each transition.
+type runeRange struct {
+	lo, hi rune
+}
 
-		// If we've read the required byte offset, stop and return the position
-		if currentByte == offset {
-			return line, column, nil
+// Checks if a rune is within a sorted list of ranges.
+func inRanges(r rune, ranges []runeRange) bool {
+	lo, hi := 0, len(ranges)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if ranges[mid].hi < r {
+			lo = mid + 1
+		} else {
+			hi = mid
 		}
+	}
+	return lo < len(ranges) && ranges[lo].lo <= r
+}
 
-		// Increment byte offset
-		currentByte++
+{{ .Scanner }}
+{{ else }}
+// Range of runes above ASCII that belongs to an equivalence class. Runes of a
+// class have the same transitions on every state, class 0 has no transitions.
+type classRange struct {
+	lo, hi rune
+	class  uint16
+}
 
-		// If the byte is a newline, increment line and reset column
-		if byteRead == '\n' {
-			line++
-			column = 1
-		} else {
-			column++
+{{ .Tables }}
+
+func scan(content string, start int) (int, int32, int) {
+	pos := start
+	state := int32(0)
+	acceptEnd := -1 // Where the longest lexeme accepted until now ends
+	acceptRule := int32(-1)
+	for pos < len(content) {
+		r, size := rune(content[pos]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRuneInString(content[pos:])
 		}
-
-		// If we've reached the end of the file, break
-		if err != nil {
+		next := transitions[int(state)*numClasses+classOf(r)]
+		if next < 0 {
 			break
 		}
+		state = next
+		pos += size
+		if rule := acceptRules[state]; rule >= 0 {
+			acceptEnd = pos
+			acceptRule = rule
+		}
 	}
-
-	return 0, 0, fmt.Errorf("Offset exceeds the number of bytes in the file")
+	return acceptEnd, acceptRule, pos
 }
 
-// =====================
-//	  DFA
-// =====================
-
-type dfa struct {
-	startState *state
-	states     []*state
+// Returns the equivalence class of a rune.
+func classOf(r rune) int {
+	if r < utf8.RuneSelf {
+		return int(asciiClasses[r])
+	}
+	lo, hi := 0, len(classRanges)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if classRanges[mid].hi < r {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(classRanges) && classRanges[lo].lo <= r {
+		return int(classRanges[lo].class)
+	}
+	return 0
+}
+{{ end }}
+// Literal rules matched by another rule (like keywords by identifiers) are
+// not part of the automata, they are looked up on perfect hash tables once
+// that other rule matches.
+type keyword struct {
+	text string
+	rule int32 // -1 for empty slots
 }
 
-type state struct {
-	id          string
-	actions     []action          // Sorted by highest too lower priority ( 0 has the hightes priority )
-	transitions map[Symbol]*state // {"a": STATE1, "b": STATE2, "NUMBER": STATEFINAL}
-	isFinal     bool
+// FNV-1a hash of a lexeme, the seed makes it perfect for a keyword table.
+func keywordHash(s string, seed uint32) uint32 {
+	h := seed ^ 2166136261
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h ^ h>>16 // Lets the whole seed reach the low bits
 }
 
-// Representes a user defined action that should happen
-// when a pattern is recognized. The function should return an int, that represents a 
-// tokenID. Its shape should be look something like : 
-// 
-// 	func () int {
-// 		tokenID := SKIP_LEXEM
-//		<user defined code>
-//		return tokenID
-//  }
-//
-type action func() int
+{{ .Keywords }}
 
-// createDFA constructs the DFA that recognizes the user language.
-func createDFA() *dfa {
-	{{ .Automata }}
+// Runs the user defined action of a rule, which returns the tokenID of the lexeme.
+// The lexer is received as "l", so actions can read the lexeme with l.Text()
+// or include files with l.PushInput(). Actions that don't return anything skip
+// the lexeme.
+func (l *Lexer) runAction(rule int) int {
+	switch rule {
+	{{ .Actions }}
+	}
+	return SKIP_LEXEME
 }
 
 // =====================
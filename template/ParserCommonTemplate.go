@@ -0,0 +1,127 @@
+{{/* Code shared by every parser template, included with: template "ParserCommon" . */}}
+{{ define "ParserCommon" }}
+// =============================
+// 		CHANNELS & PARSE TREE
+// =============================
+
+// Channels tokens can be routed to, any other name can be declared with %channel.
+// The parser only consumes tokens on the default channel.
+const (
+	DEFAULT_CHANNEL = "default"
+	HIDDEN_CHANNEL  = "hidden"
+)
+
+// Channel returns the channel a token is routed to.
+func (p *Parser) Channel(token Token) string {
+	if channel, ok := p.parsedefinition.Channels[token.TokenID]; ok {
+		return channel
+	}
+	return DEFAULT_CHANNEL
+}
+
+// SplitChannels groups the tokens by the channel they are routed to, keeping their order.
+func (p *Parser) SplitChannels(tokens []Token) map[string][]Token {
+	channels := make(map[string][]Token)
+	for _, token := range tokens {
+		channel := p.Channel(token)
+		channels[channel] = append(channels[channel], token)
+	}
+	return channels
+}
+
+// Node of the tree built by Parse. Leaves hold a token of the default channel
+// alongside the tokens of other channels around it, so tools like formatters
+// can rebuild the original text:
+//
+//	Before: tokens between the previous leaf and this one, except the ones on the previous leaf's line
+//	After:  tokens after this leaf up to the end of its line (or the file if it's the last leaf)
+type ParseNode struct {
+	Symbol   ParserSymbol
+	Children []*ParseNode // Empty for leaves
+	Token    *Token       // Only set for leaves
+	Before   []Token
+	After    []Token
+}
+
+func (n *ParseNode) IsLeaf() bool {
+	return n.Token != nil
+}
+
+// Leaves returns the leaves of the tree from left to right.
+func (n *ParseNode) Leaves() []*ParseNode {
+	if n.IsLeaf() {
+		return []*ParseNode{n}
+	}
+	leaves := make([]*ParseNode, 0)
+	for _, child := range n.Children {
+		leaves = append(leaves, child.Leaves()...)
+	}
+	return leaves
+}
+
+// SyntaxError is returned by Parse when a token is not expected by the grammar.
+type SyntaxError struct {
+	Token    Token // Token with an empty value and TokenID NO_LEXEME means the end of the input
+	Expected []string
+}
+
+func (e *SyntaxError) Error() string {
+	found := "end of input"
+	if e.Token.TokenID != NO_LEXEME {
+		found = fmt.Sprintf("%q", e.Token.Value)
+	}
+	return fmt.Sprintf("error line %d column %d \n\tunexpected %s, expected one of: %s",
+		e.Token.Line,
+		e.Token.Column,
+		found,
+		strings.Join(e.Expected, " "))
+}
+
+// Builds the error for the token at a position, or for the end of the input
+// right after the last token.
+func (p *Parser) syntaxError(leaves []*ParseNode, next int, expected []string) error {
+	found := Token{TokenID: NO_LEXEME}
+	if next < len(leaves) {
+		found = *leaves[next].Token
+	} else if len(leaves) > 0 {
+		last := leaves[len(leaves)-1].Token
+		found.Line, found.Column, found.Offset = last.Line, last.Column+len([]rune(last.Value)), last.Offset+len(last.Value)
+	}
+	return &SyntaxError{Token: found, Expected: expected}
+}
+
+// Creates a leaf for each token on the default channel, distributing the tokens
+// of the other channels between them.
+func (p *Parser) attachHidden(tokens []Token) []*ParseNode {
+	leaves := make([]*ParseNode, 0)
+	pending := make([]Token, 0) // Hidden tokens waiting for the next leaf
+
+	for i := range tokens {
+		token := tokens[i]
+		if p.Channel(token) == DEFAULT_CHANNEL {
+			leaves = append(leaves, &ParseNode{Token: &token, Before: pending})
+			pending = make([]Token, 0)
+			continue
+		}
+
+		// Hidden tokens starting on the line of the last leaf belong to it, the
+		// same line of another input pushed by the lexer doesn't count
+		if len(leaves) > 0 && len(pending) == 0 {
+			last := leaves[len(leaves)-1]
+			if token.File == last.Token.File && token.Line == last.Token.Line {
+				last.After = append(last.After, token)
+				continue
+			}
+		}
+		pending = append(pending, token)
+	}
+
+	// Tokens at the end of the file belong to the last leaf
+	if len(leaves) > 0 {
+		last := leaves[len(leaves)-1]
+		last.After = append(last.After, pending...)
+	}
+	return leaves
+}
+
+{{ end }}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -45,6 +46,7 @@ type ParserDefinition struct {
 	Terminals    []ParserSymbol
 	Productions  []ParserProduction
 	IgnoredSymbols map[int]ParserSymbol
+	Channels       map[int]string // Channel of each token off the default channel
 }
 
 // Represents a single production declaration
@@ -294,6 +296,77 @@ func (p *Parser) ParseInput(token []Token, parserterminals []ParserSymbol, parse
 
 }
 
+{{ template "ParserCommon" . }}
+// Parse builds the parse tree of a list of tokens of any channel. Only tokens on
+// the default channel are parsed, the rest are attached to the closest leaf.
+func (p *Parser) Parse(tokens []Token) (*ParseNode, error) {
+	leaves := p.attachHidden(tokens)
+
+	terminals := make(map[int]ParserSymbol)
+	for _, terminal := range p.parsedefinition.Terminals {
+		terminals[terminal.Id] = terminal
+	}
+
+	states := []string{"0"}
+	nodes := make([]*ParseNode, 0)
+	next := 0
+
+	for {
+		state := states[len(states)-1]
+		lookahead := "$"
+		if next < len(leaves) {
+			terminal, ok := terminals[leaves[next].Token.TokenID]
+			if !ok {
+				return nil, p.syntaxError(leaves, next, p.expected(state))
+			}
+			leaves[next].Symbol = terminal
+			lookahead = terminal.Value
+		}
+
+		move, ok := (*p.transitiontable)[state][lookahead]
+		if !ok {
+			return nil, p.syntaxError(leaves, next, p.expected(state))
+		}
+
+		switch move.MovementType {
+		case SHIFT:
+			nodes = append(nodes, leaves[next])
+			states = append(states, strconv.Itoa(move.NextRow))
+			next++
+
+		case REDUCE:
+			production := p.parsedefinition.Productions[move.NextRow]
+			size := len(production.Body)
+			node := &ParseNode{Symbol: production.Head, Children: append([]*ParseNode{}, nodes[len(nodes)-size:]...)}
+			nodes = nodes[:len(nodes)-size]
+			states = states[:len(states)-size]
+
+			jump, ok := (*p.gototable)[states[len(states)-1]][production.Head.Value]
+			if !ok {
+				return nil, fmt.Errorf("missing GOTO from state %s with %s", states[len(states)-1], production.Head.Value)
+			}
+			nodes = append(nodes, node)
+			states = append(states, strconv.Itoa(jump.NextRow))
+
+		case ACCEPT:
+			if len(nodes) != 1 {
+				return nil, fmt.Errorf("input accepted with %d nodes left on the stack", len(nodes))
+			}
+			return nodes[0], nil
+		}
+	}
+}
+
+// Terminals the transition table accepts on a state, sorted.
+func (p *Parser) expected(state string) []string {
+	expected := make([]string, 0)
+	for symbol := range (*p.transitiontable)[state] {
+		expected = append(expected, symbol)
+	}
+	sort.Strings(expected)
+	return expected
+}
+
 func newTransitTable() *TransitionTbl {
 	return &TransitionTbl{
 		{{- range $state, $row := .TransitTable }}
@@ -343,6 +416,7 @@ func newParserdefinition() *ParserDefinition {
 			{{- end }}
 		},
 		IgnoredSymbols: {{ goLiteral .ParserDefinition.IgnoredSymbol }},
+		Channels:       {{ goLiteral .ParserDefinition.Channels }},
 	}
 }
 {{ end }}